@@ -0,0 +1,20 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
+)
+
+type AlgorithmsResponse struct {
+	Algorithms []anagram.Algorithm `json:"algorithms"`
+}
+
+// ListAlgorithms serves the registered algorithms with their descriptions, capabilities and options.
+func ListAlgorithms(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	json.NewEncoder(w).Encode(AlgorithmsResponse{Algorithms: anagram.Algorithms()})
+}
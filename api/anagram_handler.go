@@ -1,18 +1,31 @@
 package api
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
+	"log"
+	"mime/multipart"
 	"net/http"
+	"strconv"
 	"strings"
 
 	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
 	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
 )
 
+const (
+	// maxMultipartMemory is the size of a multipart form kept in memory, larger file parts are stored in temporary files.
+	maxMultipartMemory = 32 << 20 // 32MB
+	defaultMaxBodySize = 1 << 30  // 1GB
+)
+
 type AnagramHandler struct {
 	inputSourceFactory   inputsource.InputSourceFactoryInterface
 	anagramFinderFactory anagram.AnagramFinderFactoryInterface
+	// MaxBodySize is the maximum size of a request body in bytes as sent, before it is decompressed.
+	// 1GB when zero, larger bodies are rejected with 413.
+	MaxBodySize int64
 }
 
 func NewAnagramHandler(isf inputsource.InputSourceFactoryInterface, aff anagram.AnagramFinderFactoryInterface) *AnagramHandler {
@@ -23,23 +36,30 @@ func NewAnagramHandler(isf inputsource.InputSourceFactoryInterface, aff anagram.
 }
 
 func (h *AnagramHandler) FindAnagrams(w http.ResponseWriter, r *http.Request) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.maxBodySize())
 	defer r.Body.Close()
+	defer func() {
+		if r.MultipartForm != nil {
+			r.MultipartForm.RemoveAll()
+		}
+	}()
 
 	inputSource, req, err := h.parseRequest(r)
 	if err != nil {
-		status, errMsg := handleError(err)
-		serveResponse(w, nil, status, errMsg)
+		serveError(w, err)
 		return
 	}
 
-	anagramGroups, err := h.processAnagrams(req, inputSource)
-	if err != nil {
-		status, errMsg := handleError(err)
-		serveResponse(w, nil, status, errMsg)
-		return
+	if err := h.processAnagrams(r.Context(), w, req, inputSource); err != nil {
+		serveError(w, err)
 	}
+}
 
-	serveResponse(w, anagramGroups, http.StatusOK, "")
+func (h *AnagramHandler) maxBodySize() int64 {
+	if h.MaxBodySize > 0 {
+		return h.MaxBodySize
+	}
+	return defaultMaxBodySize
 }
 
 // todo: this method does not adhere to SOLID (SRP), refactor
@@ -49,58 +69,338 @@ func (h *AnagramHandler) parseRequest(r *http.Request) (inputsource.InputSource,
 
 	switch {
 	case strings.Contains(contentType, "multipart/form-data"):
-		file, _, err := r.FormFile("file")
+		return h.parseMultipartRequest(r)
+
+	case strings.Contains(contentType, "application/json"):
+		err := json.NewDecoder(r.Body).Decode(&req)
+		if errors.As(err, new(*inputsource.WordError)) {
+			return nil, req, err
+		}
 		if err != nil {
-			return nil, req, errors.New(ErrInvalidFileInput)
+			return nil, req, formatError(err)
 		}
-		defer file.Close()
 
-		req.InputType = r.FormValue("inputType")
-		req.Algorithm = r.FormValue("algorithm")
+		if err := req.resolveInputType(); err != nil {
+			return nil, req, err
+		}
 
 		if err := req.validate(); err != nil {
 			return nil, req, err
 		}
 
-		return inputsource.NewHttpFileInputSource(file), req, nil
-
-	case strings.Contains(contentType, "application/json"):
-		err := json.NewDecoder(r.Body).Decode(&req)
+		config, err := req.inputConfig()
 		if err != nil {
-			return nil, req, errors.New(ErrInvalidFormat)
-		}
-
-		if err := req.validate(); err != nil {
 			return nil, req, err
 		}
 
-		inputSource, err := h.inputSourceFactory.CreateInputSource(req.InputType, req.InputData)
+		inputSource, err := h.createInputSource(req.InputType, config)
 		if err != nil {
 			return nil, req, err
 		}
 
 		return inputSource, req, nil
 
+	case strings.Contains(contentType, "application/x-ndjson"):
+		return h.parseBodyRequest(r, "ndjson")
+
+	case strings.Contains(contentType, "text/plain"):
+		return h.parseBodyRequest(r, "http_text")
+
 	default:
 		return nil, req, errors.New(ErrUnsupportedContentType)
 	}
 }
 
-func (h *AnagramHandler) processAnagrams(req AnagramRequest, inputSource inputsource.InputSource) ([][]string, error) {
-	anagramFinder, err := h.anagramFinderFactory.CreateAnagramFinder(req.Algorithm)
+// parseMultipartRequest reads any number of file parts, URLs and comma-separated input data from a
+// multipart form and merges them into a single input source. The file parts are read as inputType,
+// http_file by default, the URLs as http_url or, for csv, as csv documents.
+func (h *AnagramHandler) parseMultipartRequest(r *http.Request) (inputsource.InputSource, AnagramRequest, error) {
+	var req AnagramRequest
+
+	if err := r.ParseMultipartForm(maxMultipartMemory); err != nil {
+		return nil, req, formatError(err)
+	}
+
+	req.InputType = r.FormValue("inputType")
+	if req.InputType == "" {
+		req.InputType = "http_file"
+	}
+	req.Algorithm = r.FormValue("algorithm")
+	req.ResponseFormat = r.FormValue("responseFormat")
+	req.Cache = r.FormValue("cache")
+	req.Compression = r.FormValue("compression")
+	req.Charset = r.FormValue("charset")
+	req.Delimiter = r.FormValue("delimiter")
+	req.Column = r.FormValue("column")
+
+	var err error
+	if req.Header, err = parseFormBool(r.FormValue("header")); err != nil {
+		return nil, req, errors.New(ErrInvalidCsvOptions)
+	}
+	if err := req.parseTokenizeOptions(r.FormValue); err != nil {
+		return nil, req, err
+	}
+
+	if err := req.validate(); err != nil {
+		return nil, req, err
+	}
+
+	sources, err := h.multipartSources(req, r.MultipartForm)
 	if err != nil {
-		return nil, err
+		inputsource.CloseSources(sources)
+		return nil, req, err
+	}
+	if len(sources) == 0 {
+		return nil, req, errors.New(ErrMissingInput)
+	}
+
+	return inputsource.NewMultiInputSource(sources...), req, nil
+}
+
+// multipartSources creates an input source for every file part, URL and input data of the form.
+// The sources created before an error are returned for the caller to close them.
+func (h *AnagramHandler) multipartSources(req AnagramRequest, form *multipart.Form) ([]inputsource.InputSource, error) {
+	var sources []inputsource.InputSource
+
+	for _, header := range form.File["file"] {
+		file, err := header.Open()
+		if err != nil {
+			return sources, errors.New(ErrInvalidFile)
+		}
+
+		// the charset of the request applies to every part, parts may name their own charset otherwise
+		partReq := req
+		if partReq.Charset == "" {
+			partReq.Charset = inputsource.MediaTypeCharset(header.Header.Get("Content-Type"))
+		}
+
+		config, err := partReq.fileConfig(file, header.Filename)
+		if err != nil {
+			file.Close()
+			return sources, err
+		}
+
+		// the input source closes the file once it has been streamed
+		source, err := h.createInputSource(req.InputType, config)
+		if err != nil {
+			file.Close()
+			return sources, err
+		}
+		sources = append(sources, source)
+	}
+
+	inline := []struct {
+		inputType string
+		values    []string
+	}{
+		{req.urlInputType(), form.Value["url"]},
+		{"http_body", form.Value["inputData"]},
 	}
 
-	words, err := inputSource.GetWords()
+	for _, in := range inline {
+		for _, value := range in.values {
+			sourceReq := req
+			sourceReq.InputType = in.inputType
+			sourceReq.InputData = value
+
+			config, err := sourceReq.inputConfig()
+			if err != nil {
+				return sources, err
+			}
+
+			source, err := h.createInputSource(in.inputType, config)
+			if err != nil {
+				return sources, err
+			}
+			sources = append(sources, source)
+		}
+	}
+
+	return sources, nil
+}
+
+// parseBodyRequest streams the words from the request body, the options are given as query parameters.
+// The input type defaults to the input source of the content type.
+func (h *AnagramHandler) parseBodyRequest(r *http.Request, inputType string) (inputsource.InputSource, AnagramRequest, error) {
+	var req AnagramRequest
+
+	query := r.URL.Query()
+	req.InputType = query.Get("inputType")
+	if req.InputType == "" {
+		req.InputType = inputType
+	}
+	req.Algorithm = query.Get("algorithm")
+	req.ResponseFormat = query.Get("responseFormat")
+	req.Compression = query.Get("compression")
+	req.Charset = query.Get("charset")
+	if req.Charset == "" {
+		req.Charset = inputsource.MediaTypeCharset(r.Header.Get("Content-Type"))
+	}
+	if err := req.parseTokenizeOptions(query.Get); err != nil {
+		return nil, req, err
+	}
+
+	if err := req.validate(); err != nil {
+		return nil, req, err
+	}
+
+	config, err := req.bodyConfig(r.Body, r.ContentLength)
 	if err != nil {
-		return nil, err
+		return nil, req, err
 	}
 
-	anagramGroups, err := anagramFinder.FindAnagrams(words)
+	inputSource, err := h.createInputSource(req.InputType, config)
 	if err != nil {
+		return nil, req, err
+	}
+
+	return inputSource, req, nil
+}
+
+// parseFormBool parses an optional boolean form field, false when it is empty.
+func parseFormBool(value string) (bool, error) {
+	if value == "" {
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+// parseTokenizeOptions reads the tokenize options from the fields of a form or a query.
+func (req *AnagramRequest) parseTokenizeOptions(field func(name string) string) error {
+	options := []struct {
+		name  string
+		value *bool
+	}{
+		{"tokenize", &req.Tokenize},
+		{"dropPunctuation", &req.DropPunctuation},
+		{"dropNumbers", &req.DropNumbers},
+		{"stripHtml", &req.StripHtml},
+	}
+
+	for _, option := range options {
+		value, err := parseFormBool(field(option.name))
+		if err != nil {
+			return errors.New(ErrInvalidTokenizeOptions)
+		}
+		*option.value = value
+	}
+
+	return nil
+}
+
+// createInputSource maps configuration errors of the input source to client errors.
+func (h *AnagramHandler) createInputSource(inputType string, config inputsource.Config) (inputsource.InputSource, error) {
+	inputSource, err := h.inputSourceFactory.CreateInputSource(inputType, config)
+	switch {
+	case errors.Is(err, inputsource.ErrUnknownSource):
+		return nil, errors.New(ErrInvalidInputType)
+	case errors.As(err, new(*inputsource.WordError)):
 		return nil, err
+	case errors.Is(err, inputsource.ErrInvalidConfig):
+		// configs failing for a known reason, such as an unsupported charset, report it
+		if mapped := mapInputError(err); mapped != err {
+			return nil, mapped
+		}
+		log.Printf("invalid input source config: %v", err)
+		return nil, errors.New(ErrInvalidInput)
+	case err != nil:
+		return nil, mapInputError(err)
+	}
+
+	return inputSource, nil
+}
+
+// processAnagrams finds the anagram groups of the input and writes them to w as they are found. Errors
+// are returned as long as nothing has been written, afterwards they end the response.
+func (h *AnagramHandler) processAnagrams(ctx context.Context, w http.ResponseWriter, req AnagramRequest, inputSource inputsource.InputSource) error {
+	anagramFinder, err := h.anagramFinderFactory.CreateAnagramFinder(req.Algorithm)
+	if err != nil {
+		return err
+	}
+
+	// tokenizing applies to the words of every input source
+	inputSource = inputsource.NewTokenizingInputSource(inputSource, req.tokenizeOptions())
+
+	if hinter, ok := anagramFinder.(anagram.SizeHinter); ok {
+		if sizer, ok := inputSource.(inputsource.Sizer); ok {
+			hinter.SetSizeHint(sizer.Size())
+		}
+	}
+
+	gw := newGroupWriter(w, req.detailed())
+	defer gw.close()
+
+	if err := h.findAnagrams(ctx, req, inputSource, anagramFinder, gw); err != nil {
+		err = mapInputError(err)
+		if !gw.started {
+			return err
+		}
+		log.Printf("failed after writing the response: %v", err)
+		gw.fail(err)
+		return nil
+	}
+
+	var metadata *ResponseMetadata
+	if sf, ok := anagramFinder.(anagram.SelectingAnagramFinder); ok {
+		metadata = &ResponseMetadata{Algorithm: sf.SelectedAlgorithm()}
+	}
+
+	if err := gw.finish(metadata); err != nil {
+		log.Printf("failed to write the response: %v", err)
+	}
+	return nil
+}
+
+// findAnagrams passes the anagram groups of the input to gw, as they are found by finders emitting their groups.
+func (h *AnagramHandler) findAnagrams(ctx context.Context, req AnagramRequest, inputSource inputsource.InputSource, anagramFinder anagram.AnagramFinder, gw *groupWriter) error {
+	// the provenance of the words is spooled for the detailed response format, and read back for the
+	// words of the groups only
+	var provenance *provenanceSpool
+	if req.detailed() {
+		var err error
+		if provenance, err = newProvenanceSpool(); err != nil {
+			return err
+		}
+		defer provenance.close()
+		gw.provenance = provenance.lookup
+	}
+
+	stream := func(yield func(word, signature string) error) error {
+		return inputsource.StreamWords(ctx, inputSource, func(word inputsource.Word) error {
+			if provenance != nil {
+				if err := provenance.add(word); err != nil {
+					return err
+				}
+			}
+			return yield(word.Text, word.Signature)
+		})
 	}
 
-	return anagramGroups, nil
+	if ef, ok := anagramFinder.(anagram.EmittingAnagramFinder); ok {
+		return ef.EmitAnagramGroups(ctx, stream, gw.write)
+	}
+
+	if req.detailed() {
+		groups, err := anagram.StreamAnagramGroups(ctx, anagramFinder, stream)
+		if err != nil {
+			return err
+		}
+		for _, group := range groups {
+			if err := gw.write(group); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	anagramGroups, err := anagram.StreamAnagrams(ctx, anagramFinder, stream)
+	if err != nil {
+		return err
+	}
+	for _, words := range anagramGroups {
+		if err := gw.write(anagram.AnagramGroup{Words: words}); err != nil {
+			return err
+		}
+	}
+	return nil
 }
@@ -2,14 +2,20 @@ package api
 
 import (
 	"bytes"
+	"compress/gzip"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
 	"sort"
 	"strings"
 	"testing"
+	"testing/fstest"
 
 	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
 	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
@@ -29,6 +35,12 @@ func Test_SortMap_FindAnagrams_BodyInput(t *testing.T) {
 			expectedCode:   http.StatusOK,
 			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"enlist\",\"inlets\",\"silent\"],[\"cat\",\"tac\"],[\"nag a ram\",\"anagram\"]]}\n",
 		},
+		{
+			name:           "External Sort Algorithm",
+			body:           `{"inputType": "http_body", "inputData": "listen,enlist,inlets,cat,silent,tac,nag a ram,anagram", "algorithm": "external_sort"}`,
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"enlist\",\"inlets\",\"silent\"],[\"cat\",\"tac\"],[\"nag a ram\",\"anagram\"]]}\n",
+		},
 		{
 			name:          "Invalid Input Type",
 			body:          `{"inputType": "invalid", "inputData": "listen,enlist,inlets,silent", "algorithm": "sort_map"}`,
@@ -47,6 +59,84 @@ func Test_SortMap_FindAnagrams_BodyInput(t *testing.T) {
 			expectedCode:  http.StatusBadRequest,
 			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput),
 		},
+		{
+			name:          "File Input Type In Json Body",
+			body:          `{"inputType": "http_file", "inputData": "listen,silent", "algorithm": "sort_map"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput),
+		},
+		{
+			name:          "Invalid Url",
+			body:          `{"inputType": "http_url", "inputData": "not a url", "algorithm": "sort_map"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput),
+		},
+		{
+			name:          "Invalid Cache Option",
+			body:          `{"inputType": "http_body", "inputData": "tac,cat", "algorithm": "sort_map", "cache": "refresh"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidCacheOption),
+		},
+		{
+			name:           "Json Words",
+			body:           `{"inputType": "json_words", "words": ["listen", "silent", "a,b", "b,a"], "algorithm": "sort_map"}`,
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"silent\"],[\"a,b\",\"b,a\"]]}\n",
+		},
+		{
+			name:          "Json Words Empty Element",
+			body:          `{"inputType": "json_words", "words": ["listen", " ", "silent"], "algorithm": "sort_map"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s: word 2 is empty\"}", ErrInvalidWord),
+		},
+		{
+			name:          "Json Words Invalid UTF-8",
+			body:          "{\"inputType\": \"json_words\", \"words\": [\"listen\", \"silent\", \"\xff\"], \"algorithm\": \"sort_map\"}",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s: word 3 is not valid UTF-8\"}", ErrInvalidWord),
+		},
+		{
+			name:          "Json Words Not Strings",
+			body:          `{"inputType": "json_words", "words": ["listen", 1], "algorithm": "sort_map"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidFormat),
+		},
+		{
+			name:          "Json Words Single Word",
+			body:          `{"inputType": "json_words", "words": ["listen"], "algorithm": "sort_map"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput),
+		},
+		{
+			name:          "Tokenize Options Without Tokenize",
+			body:          `{"inputType": "http_body", "inputData": "tac,cat", "algorithm": "sort_map", "dropNumbers": true}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidTokenizeOptions),
+		},
+		{
+			name:           "Detailed Response Format",
+			body:           `{"inputType": "http_body", "inputData": "tac,dog,cat", "algorithm": "sort_map", "responseFormat": "detailed"}`,
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"tac\",\"cat\"]],\"groups\":[{\"signature\":\"act\",\"histogram\":{\"a\":1,\"c\":1,\"t\":1},\"size\":2,\"words\":[\"tac\",\"cat\"],\"positions\":[0,2],\"provenance\":[{\"source\":\"http_body\",\"line\":1,\"column\":1},{\"source\":\"http_body\",\"line\":1,\"column\":3}]}]}\n",
+		},
+		{
+			name:           "Detailed Response Format With External Sort",
+			body:           `{"inputType": "http_body", "inputData": "tac,dog,cat", "algorithm": "external_sort", "responseFormat": "detailed"}`,
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"tac\",\"cat\"]],\"groups\":[{\"signature\":\"act\",\"histogram\":{\"a\":1,\"c\":1,\"t\":1},\"size\":2,\"words\":[\"tac\",\"cat\"],\"positions\":[0,2],\"provenance\":[{\"source\":\"http_body\",\"line\":1,\"column\":1},{\"source\":\"http_body\",\"line\":1,\"column\":3}]}]}\n",
+		},
+		{
+			name:           "No Anagrams With External Sort",
+			body:           `{"inputType": "http_body", "inputData": "tac,dog", "algorithm": "external_sort", "responseFormat": "detailed"}`,
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[]}\n",
+		},
+		{
+			name:          "Invalid Response Format",
+			body:          `{"inputType": "http_body", "inputData": "tac,cat", "algorithm": "sort_map", "responseFormat": "verbose"}`,
+			expectedCode:  http.StatusBadRequest,
+			expectedError: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidResponseFormat),
+		},
 	}
 
 	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
@@ -82,6 +172,65 @@ func Test_SortMap_FindAnagrams_BodyInput(t *testing.T) {
 	}
 }
 
+func Test_Auto_FindAnagrams_BodyInput(t *testing.T) {
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
+
+	body := `{"inputType": "http_body", "inputData": "listen,enlist,inlets,cat,silent,tac,nag a ram,anagram", "algorithm": "auto"}`
+	req := httptest.NewRequest("POST", "/anagram", bytes.NewBuffer([]byte(body)))
+	req.Header.Set("Content-Type", "application/json")
+	rr := httptest.NewRecorder()
+
+	handler.FindAnagrams(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response AnagramResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if response.Metadata == nil || (response.Metadata.Algorithm != "sort_map" && response.Metadata.Algorithm != "compact") {
+		t.Errorf("handler returned unexpected metadata: %+v", response.Metadata)
+	}
+
+	expected := sortJsonResponse("{\"anagramGroups\":[[\"listen\",\"enlist\",\"inlets\",\"silent\"],[\"cat\",\"tac\"],[\"nag a ram\",\"anagram\"]]}\n")
+	response.Metadata = nil
+	output, _ := json.Marshal(response)
+	if actual := sortJsonResponse(string(output)); actual != expected {
+		t.Errorf("handler returned unexpected content: got %q want %q", actual, expected)
+	}
+}
+
+// compressed uploads are far larger than their size, the automatic selection must not estimate
+// the words from it
+func Test_Auto_FindAnagrams_CompressedFileInput(t *testing.T) {
+	var content strings.Builder
+	for i := 0; i < 50000; i++ {
+		fmt.Fprintf(&content, "listen%d\n", i%100)
+	}
+
+	// the compressed file would fit into the memory limit, its content does not
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{MemoryLimit: 1024 * 1024})
+	req := generateMultipartRequest(gzipString(content.String()), "http_file", "auto")
+	rr := httptest.NewRecorder()
+
+	handler.FindAnagrams(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response AnagramResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if response.Metadata == nil || response.Metadata.Algorithm != "external_sort" {
+		t.Errorf("handler returned unexpected metadata: %+v", response.Metadata)
+	}
+}
+
 // todo: implement test scenarios for
 // large files
 // different file types
@@ -107,6 +256,24 @@ func TestFindAnagrams_FileInput(t *testing.T) {
 			expectedError:      nil,
 			expectedFileOutput: "{\"anagramGroups\":[[\"listen\",\"enlist\",\"inlets\",\"silent\"],[\"cat\",\"tac\"],[\"nag a ram\",\"anagram\"]]}\n",
 		},
+		{
+			name:               "Gzip File Input",
+			fileContents:       gzipString("listen\nenlist\ninlets\ncat\nsilent\ntac\nnag a ram\nanagram"),
+			inputType:          "http_file",
+			algorithm:          "sort_map",
+			expectedCode:       http.StatusOK,
+			expectedError:      nil,
+			expectedFileOutput: "{\"anagramGroups\":[[\"listen\",\"enlist\",\"inlets\",\"silent\"],[\"cat\",\"tac\"],[\"nag a ram\",\"anagram\"]]}\n",
+		},
+		{
+			name:               "Body Input Type With File",
+			fileContents:       "listen\nsilent",
+			inputType:          "http_body",
+			algorithm:          "sort_map",
+			expectedCode:       http.StatusBadRequest,
+			expectedError:      errors.New(fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput)),
+			expectedFileOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput),
+		},
 	}
 
 	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
@@ -142,6 +309,498 @@ func TestFindAnagrams_FileInput(t *testing.T) {
 	}
 }
 
+func TestFindAnagrams_NdjsonInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		body           string
+		expectedCode   int
+		expectedOutput string
+	}{
+		{
+			name:           "Valid Ndjson",
+			query:          "?algorithm=sort_map",
+			body:           "\"listen\"\n\"silent\"\n\n\"a,b\"\n\"b,a\"\n",
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"silent\"],[\"a,b\",\"b,a\"]]}\n",
+		},
+		{
+			name:           "Invalid Line",
+			query:          "?algorithm=sort_map",
+			body:           "\"listen\"\nsilent\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s: word 2 is not a JSON string\"}\n", ErrInvalidWord),
+		},
+		{
+			name:           "Invalid UTF-8",
+			query:          "?algorithm=sort_map",
+			body:           "\"listen\"\n\"sil\xffent\"\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s: invalid utf-8 sequence in ndjson at line 2\"}\n", ErrInvalidEncoding),
+		},
+		{
+			name:           "Invalid Algorithm",
+			query:          "?algorithm=invalid",
+			body:           "\"listen\"\n\"silent\"\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidAlgorithmType),
+		},
+		{
+			name:           "Body Input Type",
+			query:          "?inputType=http_body&algorithm=sort_map",
+			body:           "\"listen\"\n\"silent\"\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidInput),
+		},
+	}
+
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/anagram"+tc.query, strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", "application/x-ndjson")
+			rr := httptest.NewRecorder()
+
+			handler.FindAnagrams(rr, req)
+
+			if status := rr.Code; status != tc.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tc.expectedCode)
+			}
+			if actual := rr.Body.String(); actual != tc.expectedOutput {
+				t.Errorf("handler returned unexpected body: got %q want %q", actual, tc.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestFindAnagrams_TextInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		query          string
+		contentType    string
+		body           string
+		expectedCode   int
+		expectedOutput string
+	}{
+		{
+			name:           "Valid Text",
+			query:          "?algorithm=sort_map",
+			body:           "listen\nsilent\ncat\ntac\ndog\n",
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"silent\"],[\"cat\",\"tac\"]]}\n",
+		},
+		{
+			name:           "Gzip Text",
+			query:          "?algorithm=sort_map&compression=gzip",
+			body:           gzipString("listen\nsilent\n"),
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"silent\"]]}\n",
+		},
+		{
+			name:           "Tokenized Text",
+			query:          "?algorithm=sort_map&tokenize=true&dropPunctuation=true",
+			body:           "Listen, it is silent.\n",
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"Listen\",\"silent\"]]}\n",
+		},
+		{
+			name:           "Latin-1 Text",
+			query:          "?algorithm=sort_map",
+			contentType:    "text/plain; charset=ISO-8859-1",
+			body:           "caf\xe9\n\xe9fac\n",
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"café\",\"éfac\"]]}\n",
+		},
+		{
+			name:           "UTF-16 Text With BOM",
+			query:          "?algorithm=sort_map",
+			body:           "\xff\xfec\x00a\x00t\x00\n\x00t\x00a\x00c\x00",
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"cat\",\"tac\"]]}\n",
+		},
+		{
+			name:           "Invalid UTF-8",
+			query:          "?algorithm=sort_map",
+			body:           "cat\ntac\nsil\xe9nt\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s: invalid utf-8 sequence in http_text at line 3\"}\n", ErrInvalidEncoding),
+		},
+		{
+			name:           "Unsupported Charset",
+			query:          "?algorithm=sort_map&charset=koi8-r",
+			body:           "cat\ntac\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidCharset),
+		},
+		{
+			name:           "Missing Algorithm",
+			body:           "listen\nsilent\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidAlgorithmType),
+		},
+		{
+			name:           "File Input Type",
+			query:          "?inputType=http_file&algorithm=sort_map",
+			body:           "listen\nsilent\n",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidInput),
+		},
+	}
+
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/anagram"+tc.query, strings.NewReader(tc.body))
+			contentType := tc.contentType
+			if contentType == "" {
+				contentType = "text/plain"
+			}
+			req.Header.Set("Content-Type", contentType)
+			rr := httptest.NewRecorder()
+
+			handler.FindAnagrams(rr, req)
+
+			if status := rr.Code; status != tc.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tc.expectedCode)
+			}
+			if actual := rr.Body.String(); actual != tc.expectedOutput {
+				t.Errorf("handler returned unexpected body: got %q want %q", actual, tc.expectedOutput)
+			}
+		})
+	}
+}
+
+func TestFindAnagrams_BodyTooLarge(t *testing.T) {
+	var form bytes.Buffer
+	writer := multipart.NewWriter(&form)
+	writer.WriteField("algorithm", "sort_map")
+	part, _ := writer.CreateFormFile("file", "words.txt")
+	part.Write([]byte(strings.Repeat("listen\nsilent\n", 10)))
+	writer.Close()
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		within      bool
+	}{
+		{name: "Text", contentType: "text/plain", body: strings.Repeat("listen\nsilent\n", 10)},
+		// the limit applies to the body as sent, a compressed body may decompress beyond it
+		{name: "Gzip Text", contentType: "text/plain", body: gzipString(strings.Repeat("listen\nsilent\n", 100)), within: true},
+		{name: "Json", contentType: "application/json", body: `{"inputType": "http_body", "inputData": "` + strings.Repeat("listen,", 20) + `silent", "algorithm": "sort_map"}`},
+		{name: "Multipart", contentType: writer.FormDataContentType(), body: form.String()},
+	}
+
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
+	handler.MaxBodySize = 64
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/anagram?algorithm=sort_map", strings.NewReader(tc.body))
+			req.Header.Set("Content-Type", tc.contentType)
+			rr := httptest.NewRecorder()
+
+			handler.FindAnagrams(rr, req)
+
+			if tc.within {
+				if status := rr.Code; status != http.StatusOK {
+					t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+				}
+				return
+			}
+			if status := rr.Code; status != http.StatusRequestEntityTooLarge {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusRequestEntityTooLarge)
+			}
+			expected := fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrBodyTooLarge)
+			if actual := rr.Body.String(); actual != expected {
+				t.Errorf("handler returned unexpected body: got %q want %q", actual, expected)
+			}
+		})
+	}
+}
+
+func TestFindAnagrams_MixedSources(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "tinsel\ndog\n")
+	}))
+	defer server.Close()
+
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	for name, contents := range map[string]string{"north.txt": "listen\ncat\n", "south.txt": "silent\ntac\n"} {
+		part, _ := writer.CreateFormFile("file", name)
+		part.Write([]byte(contents))
+	}
+	writer.WriteField("url", server.URL+"/west.txt")
+	writer.WriteField("inputData", "enlist,god")
+	writer.WriteField("algorithm", "sort_map")
+	writer.WriteField("responseFormat", "detailed")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/anagram", body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	policy := inputsource.URLPolicy{AllowedHosts: []string{"127.0.0.1"}}
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{URLPolicy: policy}, &anagram.AnagramFinderFactory{})
+	handler.FindAnagrams(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v: %s", status, http.StatusOK, rr.Body.String())
+	}
+
+	var response AnagramResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	sources := make(map[string][]string)
+	for _, group := range response.Groups {
+		for i, word := range group.Words {
+			sources[word] = append(sources[word], group.Provenance[i].Source)
+		}
+	}
+
+	expected := map[string][]string{
+		"listen": {"north.txt"},
+		"cat":    {"north.txt"},
+		"silent": {"south.txt"},
+		"tac":    {"south.txt"},
+		"tinsel": {server.URL + "/west.txt"},
+		"enlist": {"http_body"},
+		"dog":    {server.URL + "/west.txt"},
+		"god":    {"http_body"},
+	}
+	if !reflect.DeepEqual(sources, expected) {
+		t.Errorf("handler returned unexpected sources: got %v want %v", sources, expected)
+	}
+}
+
+func TestFindAnagrams_MissingMultipartInput(t *testing.T) {
+	body := &bytes.Buffer{}
+	writer := multipart.NewWriter(body)
+	writer.WriteField("algorithm", "sort_map")
+	writer.Close()
+
+	req := httptest.NewRequest("POST", "/anagram", body)
+	req.Header.Add("Content-Type", writer.FormDataContentType())
+	rr := httptest.NewRecorder()
+
+	NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{}).FindAnagrams(rr, req)
+
+	expected := fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrMissingInput)
+	if rr.Code != http.StatusBadRequest || rr.Body.String() != expected {
+		t.Errorf("handler returned unexpected response: got %v %q want %v %q", rr.Code, rr.Body.String(), http.StatusBadRequest, expected)
+	}
+}
+
+func TestFindAnagrams_FsInput(t *testing.T) {
+	root := t.TempDir()
+	os.WriteFile(filepath.Join(root, "a.txt"), []byte("listen\nsilent\n"), 0o644)
+	os.WriteFile(filepath.Join(root, "b.txt"), []byte("enlist\n"), 0o644)
+
+	tests := []struct {
+		name     string
+		root     string
+		path     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "Glob",
+			root:     root,
+			path:     "*.txt",
+			code:     http.StatusOK,
+			expected: "{\"anagramGroups\":[[\"listen\",\"silent\",\"enlist\"]]}\n",
+		},
+		{
+			name:     "Escaping path",
+			root:     root,
+			path:     "../words.txt",
+			code:     http.StatusForbidden,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrFsPath),
+		},
+		{
+			name:     "No match",
+			root:     root,
+			path:     "*.csv",
+			code:     http.StatusNotFound,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrFsNotFound),
+		},
+		{
+			name:     "Disabled",
+			path:     "*.txt",
+			code:     http.StatusBadRequest,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrFsDisabled),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"inputType":"fs","inputData":%q,"algorithm":"sort_map"}`, tc.path)
+			req := httptest.NewRequest("POST", "/anagram", strings.NewReader(body))
+			req.Header.Add("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler := NewAnagramHandler(&inputsource.InputSourceFactory{FSRoot: tc.root}, &anagram.AnagramFinderFactory{})
+			handler.FindAnagrams(rr, req)
+
+			if rr.Code != tc.code || rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected response: got %v %q want %v %q", rr.Code, rr.Body.String(), tc.code, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindAnagrams_ObjectStoreInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		if r.URL.Path != "/corpora/words.txt" {
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, "<Error><Code>NoSuchKey</Code></Error>")
+			return
+		}
+		fmt.Fprint(w, "listen\nsilent\n")
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		store    inputsource.ObjectStore
+		key      string
+		code     int
+		expected string
+	}{
+		{
+			name:     "Object",
+			store:    inputsource.ObjectStore{Endpoint: server.URL},
+			key:      "corpora/words.txt",
+			code:     http.StatusOK,
+			expected: "{\"anagramGroups\":[[\"listen\",\"silent\"]]}\n",
+		},
+		{
+			name:     "Missing object",
+			store:    inputsource.ObjectStore{Endpoint: server.URL},
+			key:      "corpora/other.txt",
+			code:     http.StatusNotFound,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrObjectNotFound),
+		},
+		{
+			name:     "Bucket not allowed",
+			store:    inputsource.ObjectStore{Endpoint: server.URL},
+			key:      "private/words.txt",
+			code:     http.StatusForbidden,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrObjectStoreBucket),
+		},
+		{
+			name:     "Disabled",
+			key:      "corpora/words.txt",
+			code:     http.StatusBadRequest,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrObjectStoreDisabled),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := fmt.Sprintf(`{"inputType":"object_store","inputData":%q,"algorithm":"sort_map"}`, tc.key)
+			req := httptest.NewRequest("POST", "/anagram", strings.NewReader(body))
+			req.Header.Add("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			isf := &inputsource.InputSourceFactory{ObjectStore: tc.store, ObjectStoreBuckets: []string{"corpora"}}
+			handler := NewAnagramHandler(isf, &anagram.AnagramFinderFactory{})
+			handler.FindAnagrams(rr, req)
+
+			if rr.Code != tc.code || rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected response: got %v %q want %v %q", rr.Code, rr.Body.String(), tc.code, tc.expected)
+			}
+		})
+	}
+}
+
+func TestFindAnagrams_CsvInput(t *testing.T) {
+	tests := []struct {
+		name           string
+		content        string
+		fields         map[string]string
+		expectedCode   int
+		expectedOutput string
+	}{
+		{
+			name:           "Column By Name",
+			content:        "id;word\n1;\"a;b\"\n2;cat\n3;\"b;a\"\n4;tac\n",
+			fields:         map[string]string{"delimiter": ";", "column": "word", "header": "true"},
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"a;b\",\"b;a\"],[\"cat\",\"tac\"]]}\n",
+		},
+		{
+			name:           "Unknown Column",
+			content:        "id,word\n1,cat\n",
+			fields:         map[string]string{"column": "text", "header": "true"},
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrCsvColumn),
+		},
+		{
+			name:           "Column Name Without Header",
+			content:        "id,word\n1,cat\n",
+			fields:         map[string]string{"column": "word"},
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidCsvOptions),
+		},
+		{
+			name:           "Invalid Header Field",
+			content:        "id,word\n1,cat\n",
+			fields:         map[string]string{"header": "maybe"},
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidCsvOptions),
+		},
+	}
+
+	handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			body := &bytes.Buffer{}
+			writer := multipart.NewWriter(body)
+			part, _ := writer.CreateFormFile("file", "words.csv")
+			part.Write([]byte(tc.content))
+
+			writer.WriteField("inputType", "csv")
+			writer.WriteField("algorithm", "sort_map")
+			for name, value := range tc.fields {
+				writer.WriteField(name, value)
+			}
+			writer.Close()
+
+			req := httptest.NewRequest("POST", "/anagram", body)
+			req.Header.Add("Content-Type", writer.FormDataContentType())
+			rr := httptest.NewRecorder()
+
+			handler.FindAnagrams(rr, req)
+
+			if status := rr.Code; status != tc.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tc.expectedCode)
+			}
+			if actual := sortJsonResponse(rr.Body.String()); actual != sortJsonResponse(tc.expectedOutput) {
+				t.Errorf("handler returned unexpected body: got %q want %q", rr.Body.String(), tc.expectedOutput)
+			}
+		})
+	}
+}
+
+func gzipString(s string) string {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write([]byte(s))
+	zw.Close()
+	return buf.String()
+}
+
 func generateMultipartRequest(fileContents, inputType, algorithm string) *http.Request {
 	body := &bytes.Buffer{}
 	writer := multipart.NewWriter(body)
@@ -175,3 +834,229 @@ func sortJsonResponse(jsonStr string) string {
 	output, _ := json.Marshal(response)
 	return string(output)
 }
+
+func TestListAlgorithms(t *testing.T) {
+	req := httptest.NewRequest("GET", "/algorithms", nil)
+	rr := httptest.NewRecorder()
+
+	ListAlgorithms(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response AlgorithmsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var names []string
+	for _, algorithm := range response.Algorithms {
+		names = append(names, algorithm.Name)
+		if algorithm.Description == "" {
+			t.Errorf("algorithm %s has no description", algorithm.Name)
+		}
+	}
+
+	if expected := anagram.AlgorithmNames(); strings.Join(names, ",") != strings.Join(expected, ",") {
+		t.Errorf("handler returned unexpected algorithms: got %v want %v", names, expected)
+	}
+}
+
+func TestFindAnagrams_DictionaryInput(t *testing.T) {
+	err := inputsource.RegisterDictionaries(fstest.MapFS{"api-test.txt": {Data: []byte("# language: en\nlisten\nsilent\ncat\n")}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		body     string
+		code     int
+		expected string
+	}{
+		{
+			name:     "Qualified input type",
+			body:     `{"inputType":"dictionary:api-test","algorithm":"sort_map"}`,
+			code:     http.StatusOK,
+			expected: "{\"anagramGroups\":[[\"listen\",\"silent\"]]}\n",
+		},
+		{
+			name:     "Input data",
+			body:     `{"inputType":"dictionary","inputData":"api-test","algorithm":"sort_map"}`,
+			code:     http.StatusOK,
+			expected: "{\"anagramGroups\":[[\"listen\",\"silent\"]]}\n",
+		},
+		{
+			name:     "Unknown dictionary",
+			body:     `{"inputType":"dictionary:xx-none","algorithm":"sort_map"}`,
+			code:     http.StatusNotFound,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrUnknownDictionary),
+		},
+		{
+			name:     "Input data given twice",
+			body:     `{"inputType":"dictionary:api-test","inputData":"en-basic","algorithm":"sort_map"}`,
+			code:     http.StatusBadRequest,
+			expected: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}\n", ErrInvalidInput),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/anagram", strings.NewReader(tc.body))
+			req.Header.Add("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler := NewAnagramHandler(&inputsource.InputSourceFactory{}, &anagram.AnagramFinderFactory{})
+			handler.FindAnagrams(rr, req)
+
+			if rr.Code != tc.code || rr.Body.String() != tc.expected {
+				t.Errorf("handler returned unexpected response: got %v %q want %v %q", rr.Code, rr.Body.String(), tc.code, tc.expected)
+			}
+		})
+	}
+}
+
+func TestListDictionaries(t *testing.T) {
+	req := httptest.NewRequest("GET", "/dictionaries", nil)
+	rr := httptest.NewRecorder()
+
+	ListDictionaries(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var response DictionariesResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &response); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	found := false
+	for _, d := range response.Dictionaries {
+		if d.Words == 0 || d.Language == "" {
+			t.Errorf("dictionary %s has no words or language", d.Name)
+		}
+		if d.Name == "en-basic" {
+			found = d.Embedded && d.Language == "en"
+		}
+	}
+	if !found {
+		t.Errorf("handler returned no embedded en-basic dictionary: %s", rr.Body.String())
+	}
+}
+
+func TestFindAnagrams_UrlInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/words.txt":
+			fmt.Fprint(w, "listen\nenlist\ncat\ntac\ndog\n")
+		case "/words.bin":
+			w.Header().Set("Content-Type", "application/octet-stream")
+			fmt.Fprint(w, "listen\nenlist\n")
+		case "/page.html":
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			fmt.Fprint(w, "<html><script>var dog = 1;</script><body><p>Listen, the cat is silent.</p>\n<p>Act 3: god</p></body></html>")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name           string
+		url            string
+		options        string
+		policy         inputsource.URLPolicy
+		expectedCode   int
+		expectedOutput string
+	}{
+		{
+			name:           "Internal Url",
+			url:            server.URL + "/words.txt",
+			expectedCode:   http.StatusForbidden,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrUrlBlocked),
+		},
+		{
+			name:           "Valid Url",
+			policy:         inputsource.URLPolicy{AllowedHosts: []string{"127.0.0.1"}},
+			url:            server.URL + "/words.txt",
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"listen\",\"enlist\"],[\"cat\",\"tac\"]]}\n",
+		},
+		{
+			name:           "Html Page",
+			policy:         inputsource.URLPolicy{AllowedHosts: []string{"127.0.0.1"}},
+			url:            server.URL + "/page.html",
+			options:        `, "tokenize": true, "dropPunctuation": true, "dropNumbers": true, "stripHtml": true`,
+			expectedCode:   http.StatusOK,
+			expectedOutput: "{\"anagramGroups\":[[\"Listen\",\"silent\"],[\"cat\",\"Act\"]]}\n",
+		},
+		{
+			name:           "Missing Document",
+			policy:         inputsource.URLPolicy{AllowedHosts: []string{"127.0.0.1"}},
+			url:            server.URL + "/missing.txt",
+			expectedCode:   http.StatusBadGateway,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrUrlStatus),
+		},
+		{
+			name:           "Binary Document",
+			policy:         inputsource.URLPolicy{AllowedHosts: []string{"127.0.0.1"}},
+			url:            server.URL + "/words.bin",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrUrlContentType),
+		},
+		{
+			name:           "Unsupported Scheme",
+			url:            "ftp://example.com/words.txt",
+			expectedCode:   http.StatusBadRequest,
+			expectedOutput: fmt.Sprintf("{\"anagramGroups\":null,\"error\":\"%s\"}", ErrInvalidInput),
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			handler := NewAnagramHandler(&inputsource.InputSourceFactory{URLPolicy: tc.policy}, &anagram.AnagramFinderFactory{})
+
+			body := fmt.Sprintf(`{"inputType": "http_url", "inputData": %q, "algorithm": "sort_map"%s}`, tc.url, tc.options)
+			req := httptest.NewRequest("POST", "/anagram", bytes.NewBufferString(body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+
+			handler.FindAnagrams(rr, req)
+
+			if status := rr.Code; status != tc.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tc.expectedCode)
+			}
+
+			actualSorted := sortJsonResponse(rr.Body.String())
+			expectedSorted := sortJsonResponse(tc.expectedOutput)
+			if actualSorted != expectedSorted {
+				t.Errorf("handler returned unexpected content: got %q want %q", actualSorted, expectedSorted)
+			}
+		})
+	}
+}
+
+func TestGroupWriter_FailAfterStart(t *testing.T) {
+	rr := httptest.NewRecorder()
+	gw := newGroupWriter(rr, false)
+	defer gw.close()
+
+	if err := gw.write(anagram.AnagramGroup{Words: []string{"tac", "cat"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	gw.fail(errors.New(ErrUrlTimeout))
+
+	resp := rr.Result()
+	if resp.StatusCode != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, resp.StatusCode)
+	}
+	expected := fmt.Sprintf("{\"anagramGroups\":[[\"tac\",\"cat\"]],\"error\":\"%s\"}\n", ErrUrlTimeout)
+	if body := rr.Body.String(); body != expected {
+		t.Errorf("expected body %q, got %q", expected, body)
+	}
+	if trailer := resp.Trailer.Get("Error"); trailer != ErrUrlTimeout {
+		t.Errorf("expected Error trailer %q, got %q", ErrUrlTimeout, trailer)
+	}
+}
@@ -1,21 +1,70 @@
 package api
 
 import (
+	"encoding"
+	"encoding/json"
 	"errors"
+	"io"
+	"mime/multipart"
 	"strings"
+	"unicode/utf8"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
+	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
 )
 
 const (
-	inputTypeFile    = "http_file"
-	inputTypeBody    = "http_body"
-	inputTypeUrl     = "http_url"
-	algorithmSortMap = "sort_map"
+	responseFormatPlain    = "plain"
+	responseFormatDetailed = "detailed"
 )
 
 type AnagramRequest struct {
 	InputType string `json:"inputType"`
 	InputData string `json:"inputData"`
-	Algorithm string `json:"algorithm"`
+	// Words holds the input of the json_words input type.
+	Words     jsonWords `json:"words"`
+	Algorithm string    `json:"algorithm"`
+	// ResponseFormat is either plain (default) or detailed.
+	ResponseFormat string `json:"responseFormat"`
+	// Cache is empty to use the cache of URL inputs or bypass to download the URL again.
+	Cache string `json:"cache"`
+	// Compression is the format of file and URL inputs, detected by their magic bytes when empty.
+	Compression string `json:"compression"`
+	// Charset is the charset of file, URL and text inputs, given by a byte order mark or their content type when empty.
+	Charset string `json:"charset"`
+	// Delimiter, Column and Header select the column of the csv input type.
+	Delimiter string `json:"delimiter"`
+	Column    string `json:"column"`
+	Header    bool   `json:"header"`
+	// Tokenize splits the input as running text, optionally dropping punctuation and numbers and stripping HTML.
+	Tokenize        bool `json:"tokenize"`
+	DropPunctuation bool `json:"dropPunctuation"`
+	DropNumbers     bool `json:"dropNumbers"`
+	StripHtml       bool `json:"stripHtml"`
+}
+
+// jsonWords is the words of the json_words input type. encoding/json replaces invalid UTF-8 in strings
+// silently, so each word is checked before it is decoded.
+type jsonWords []string
+
+func (w *jsonWords) UnmarshalJSON(data []byte) error {
+	var raw []json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	words := make(jsonWords, len(raw))
+	for i, word := range raw {
+		if !utf8.Valid(word) {
+			return &inputsource.WordError{Position: i + 1, Reason: "is not valid UTF-8"}
+		}
+		if err := json.Unmarshal(word, &words[i]); err != nil {
+			return err
+		}
+	}
+	*w = words
+
+	return nil
 }
 
 func (req *AnagramRequest) validate() error {
@@ -27,20 +76,51 @@ func (req *AnagramRequest) validate() error {
 		return err
 	}
 
-	if err := req.validateInputData(); err != nil {
+	if err := req.validateResponseFormat(); err != nil {
+		return err
+	}
+
+	if err := req.validateCache(); err != nil {
 		return err
 	}
 
+	if err := req.validateCompression(); err != nil {
+		return err
+	}
+
+	if _, err := inputsource.CanonicalCharset(req.Charset); err != nil {
+		return errors.New(ErrInvalidCharset)
+	}
+
+	if err := req.csvOptions().Validate(); err != nil {
+		return errors.New(ErrInvalidCsvOptions)
+	}
+
+	if err := req.tokenizeOptions().Validate(); err != nil {
+		return errors.New(ErrInvalidTokenizeOptions)
+	}
+
 	return nil
 }
 
-func (req *AnagramRequest) validateInputType() error {
-	supportedTypes := map[string]bool{
-		inputTypeBody: true,
-		inputTypeFile: true,
+// resolveInputType splits an input type naming its input data, such as dictionary:en-basic, into the
+// input type and the input data. The input data must not be given twice.
+func (req *AnagramRequest) resolveInputType() error {
+	inputType, data, ok := strings.Cut(req.InputType, ":")
+	if !ok {
+		return nil
 	}
+	if req.InputData != "" {
+		return errors.New(ErrInvalidInput)
+	}
+
+	req.InputType, req.InputData = inputType, data
+
+	return nil
+}
 
-	if !supportedTypes[req.InputType] {
+func (req *AnagramRequest) validateInputType() error {
+	if _, err := inputsource.NewConfig(req.InputType); err != nil {
 		return errors.New(ErrInvalidInputType)
 	}
 
@@ -48,33 +128,138 @@ func (req *AnagramRequest) validateInputType() error {
 }
 
 func (req *AnagramRequest) validateAlgorithm() error {
-	supportedAlgorithms := map[string]bool{
-		algorithmSortMap: true,
-	}
-
-	if !supportedAlgorithms[req.Algorithm] {
+	if _, ok := anagram.LookupAlgorithm(req.Algorithm); !ok {
 		return errors.New(ErrInvalidAlgorithmType)
 	}
 
 	return nil
 }
 
-func (req *AnagramRequest) validateInputData() error {
-	// todo: improve file and http body input validations
-	switch req.InputType {
-	case inputTypeBody:
-		if req.InputData == "" || len(strings.Split(req.InputData, ",")) < 2 {
-			return errors.New(ErrInvalidInput)
-		}
-	case inputTypeFile:
-		if req.InputData != "" {
-			return errors.New(ErrInvalidFileInput)
-		}
-	case inputTypeUrl:
-		if req.InputData != "" {
-			return errors.New(ErrInvalidInput)
+// inputConfig returns the config of the input source from the input data or the words of a JSON request.
+// Input sources accepting neither, such as file uploads, cannot be used from JSON.
+func (req *AnagramRequest) inputConfig() (inputsource.Config, error) {
+	config, err := inputsource.NewConfig(req.InputType)
+	if err != nil {
+		return nil, errors.New(ErrInvalidInputType)
+	}
+
+	switch c := config.(type) {
+	case encoding.TextUnmarshaler:
+		if err := c.UnmarshalText([]byte(req.InputData)); err != nil {
+			return nil, errors.New(ErrInvalidInput)
 		}
+	case inputsource.WordsConfig:
+		c.SetWords(req.Words)
+	default:
+		return nil, errors.New(ErrInvalidInput)
 	}
 
-	return nil
+	req.setOptions(config)
+
+	return config, nil
+}
+
+// fileConfig returns the config of the input source from an uploaded file.
+func (req *AnagramRequest) fileConfig(file multipart.File, name string) (inputsource.Config, error) {
+	config, err := inputsource.NewConfig(req.InputType)
+	if err != nil {
+		return nil, errors.New(ErrInvalidInputType)
+	}
+
+	fc, ok := config.(inputsource.FileConfig)
+	if !ok {
+		return nil, errors.New(ErrInvalidInput)
+	}
+	fc.SetFile(file, name)
+
+	req.setOptions(config)
+
+	return config, nil
+}
+
+// bodyConfig returns the config of the input source streaming the request body.
+func (req *AnagramRequest) bodyConfig(body io.Reader, size int64) (inputsource.Config, error) {
+	config, err := inputsource.NewConfig(req.InputType)
+	if err != nil {
+		return nil, errors.New(ErrInvalidInputType)
+	}
+
+	bc, ok := config.(inputsource.BodyConfig)
+	if !ok {
+		return nil, errors.New(ErrInvalidInput)
+	}
+	bc.SetBody(body, size)
+
+	req.setOptions(config)
+
+	return config, nil
+}
+
+// setOptions sets the request options on the config, they are ignored by input sources not supporting them.
+func (req *AnagramRequest) setOptions(config inputsource.Config) {
+	if cc, ok := config.(inputsource.CachingConfig); ok {
+		cc.SetCache(req.Cache)
+	}
+	if cc, ok := config.(inputsource.CompressionConfig); ok {
+		cc.SetCompression(req.Compression)
+	}
+	if cc, ok := config.(inputsource.CharsetConfig); ok {
+		cc.SetCharset(req.Charset)
+	}
+	if cc, ok := config.(inputsource.CSVConfig); ok {
+		cc.SetCSVOptions(req.csvOptions())
+	}
+}
+
+// urlInputType is the input type of URLs given along with files, csv documents are read with the csv options.
+func (req *AnagramRequest) urlInputType() string {
+	if req.InputType == "csv" {
+		return "csv"
+	}
+	return "http_url"
+}
+
+func (req *AnagramRequest) csvOptions() inputsource.CSVOptions {
+	return inputsource.CSVOptions{Delimiter: req.Delimiter, Column: req.Column, Header: req.Header}
+}
+
+func (req *AnagramRequest) tokenizeOptions() inputsource.TokenizeOptions {
+	return inputsource.TokenizeOptions{
+		Enabled:         req.Tokenize,
+		DropPunctuation: req.DropPunctuation,
+		DropNumbers:     req.DropNumbers,
+		StripHtml:       req.StripHtml,
+	}
+}
+
+func (req *AnagramRequest) validateResponseFormat() error {
+	switch req.ResponseFormat {
+	case "", responseFormatPlain, responseFormatDetailed:
+		return nil
+	default:
+		return errors.New(ErrInvalidResponseFormat)
+	}
+}
+
+func (req *AnagramRequest) validateCache() error {
+	switch req.Cache {
+	case inputsource.CacheDefault, inputsource.CacheBypass:
+		return nil
+	default:
+		return errors.New(ErrInvalidCacheOption)
+	}
+}
+
+func (req *AnagramRequest) validateCompression() error {
+	switch req.Compression {
+	case inputsource.CompressionAuto, inputsource.CompressionPlain, inputsource.CompressionGzip,
+		inputsource.CompressionBzip2, inputsource.CompressionZip, inputsource.CompressionTar:
+		return nil
+	default:
+		return errors.New(ErrInvalidCompression)
+	}
+}
+
+func (req *AnagramRequest) detailed() bool {
+	return req.ResponseFormat == responseFormatDetailed
 }
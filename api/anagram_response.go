@@ -1,25 +1,212 @@
 package api
 
 import (
+	"bufio"
 	"encoding/json"
+	"io"
 	"net/http"
+	"os"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
 )
 
 type AnagramResponse struct {
-	AnagramGroups [][]string `json:"anagramGroups"`
-	Error         string     `json:"error,omitempty"`
+	AnagramGroups [][]string             `json:"anagramGroups"`
+	Groups        []AnagramGroupResponse `json:"groups,omitempty"`
+	Metadata      *ResponseMetadata      `json:"metadata,omitempty"`
+	Error         string                 `json:"error,omitempty"`
+}
+
+// ResponseMetadata is present when the algorithm was selected automatically.
+type ResponseMetadata struct {
+	Algorithm string `json:"algorithm"`
+}
+
+// AnagramGroupResponse describes a single group in the detailed response format.
+// Positions are the zero-based indexes of the words in the input.
+type AnagramGroupResponse struct {
+	Signature string         `json:"signature"`
+	Histogram map[string]int `json:"histogram"`
+	Size      int            `json:"size"`
+	Words     []string       `json:"words"`
+	Positions []int          `json:"positions"`
+	// Provenance holds where each word of the group was read from, in the order of Words.
+	Provenance []WordProvenance `json:"provenance"`
+}
+
+type WordProvenance struct {
+	Source string `json:"source"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
 }
 
 type ResponseServer struct{}
 
-func serveResponse(w http.ResponseWriter, anagramGroups [][]string, status int, err string) {
-	w.Header().Set("Content-Type", "application/json")
-	w.WriteHeader(status)
+func newAnagramGroupResponse(group anagram.AnagramGroup, provenance []WordProvenance) AnagramGroupResponse {
+	return AnagramGroupResponse{
+		Signature:  group.Signature,
+		Histogram:  group.Histogram,
+		Size:       len(group.Words),
+		Words:      group.Words,
+		Positions:  group.Positions,
+		Provenance: provenance,
+	}
+}
+
+// errorTrailer is the trailer announced by streamed responses, set when they fail after the status was sent.
+const errorTrailer = "Error"
+
+// groupWriter writes an AnagramResponse group by group, so that the groups emitted by finders such as
+// external_sort are not held in memory. The status and the start of the response are written with the
+// first group, errors occurring before it are served as usual, errors occurring after it end the body
+// with an error field and are sent as the Error trailer while the status remains 200. The detailed
+// groups are spooled to a temporary file and written after the plain groups, keeping the layout of
+// AnagramResponse.
+type groupWriter struct {
+	w        http.ResponseWriter
+	out      *bufio.Writer
+	detailed bool
+	// provenance returns where the word at a position of the input was read from.
+	provenance func(position int) (WordProvenance, error)
 
-	resp := AnagramResponse{
-		AnagramGroups: anagramGroups,
-		Error:         err,
+	spool    *os.File
+	spoolOut *bufio.Writer
+	started  bool
+	groups   int
+}
+
+func newGroupWriter(w http.ResponseWriter, detailed bool) *groupWriter {
+	return &groupWriter{w: w, detailed: detailed}
+}
+
+func (gw *groupWriter) start() error {
+	gw.started = true
+	gw.w.Header().Set("Content-Type", "application/json")
+	gw.w.Header().Set("Trailer", errorTrailer)
+	gw.w.WriteHeader(http.StatusOK)
+	gw.out = bufio.NewWriter(gw.w)
+
+	if gw.detailed {
+		spool, err := os.CreateTemp("", "anagram-groups-")
+		if err != nil {
+			return err
+		}
+		gw.spool = spool
+		gw.spoolOut = bufio.NewWriter(spool)
+	}
+
+	_, err := gw.out.WriteString(`{"anagramGroups":[`)
+	return err
+}
+
+func (gw *groupWriter) write(group anagram.AnagramGroup) error {
+	if !gw.started {
+		if err := gw.start(); err != nil {
+			return err
+		}
+	}
+	if gw.groups > 0 {
+		gw.out.WriteByte(',')
+	}
+
+	words, err := json.Marshal(group.Words)
+	if err != nil {
+		return err
+	}
+	if _, err := gw.out.Write(words); err != nil {
+		return err
 	}
 
+	if gw.detailed {
+		provenance := make([]WordProvenance, len(group.Positions))
+		for i, position := range group.Positions {
+			if provenance[i], err = gw.provenance(position); err != nil {
+				return err
+			}
+		}
+
+		detailed, err := json.Marshal(newAnagramGroupResponse(group, provenance))
+		if err != nil {
+			return err
+		}
+		if gw.groups > 0 {
+			gw.spoolOut.WriteByte(',')
+		}
+		if _, err := gw.spoolOut.Write(detailed); err != nil {
+			return err
+		}
+	}
+
+	gw.groups++
+	return nil
+}
+
+// finish completes the response with the spooled detailed groups and the metadata.
+func (gw *groupWriter) finish(metadata *ResponseMetadata) error {
+	if !gw.started {
+		if err := gw.start(); err != nil {
+			return err
+		}
+	}
+	gw.out.WriteByte(']')
+
+	if gw.detailed && gw.groups > 0 {
+		if err := gw.spoolOut.Flush(); err != nil {
+			return err
+		}
+		if _, err := gw.spool.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		gw.out.WriteString(`,"groups":[`)
+		if _, err := io.Copy(gw.out, gw.spool); err != nil {
+			return err
+		}
+		gw.out.WriteByte(']')
+	}
+
+	if metadata != nil {
+		encoded, err := json.Marshal(metadata)
+		if err != nil {
+			return err
+		}
+		gw.out.WriteString(`,"metadata":`)
+		gw.out.Write(encoded)
+	}
+
+	gw.out.WriteString("}\n")
+	return gw.out.Flush()
+}
+
+// fail ends a started response with the error, its status has already been sent. The error is also
+// set as the trailer, for clients to detect the failure without parsing the body.
+func (gw *groupWriter) fail(err error) {
+	_, message := handleError(err)
+	encoded, _ := json.Marshal(message)
+
+	gw.out.WriteString(`],"error":`)
+	gw.out.Write(encoded)
+	gw.out.WriteString("}\n")
+	gw.out.Flush()
+
+	gw.w.Header().Set(errorTrailer, message)
+}
+
+// close removes the spooled detailed groups.
+func (gw *groupWriter) close() {
+	if gw.spool != nil {
+		gw.spool.Close()
+		os.Remove(gw.spool.Name())
+	}
+}
+
+func serveResponse(w http.ResponseWriter, resp AnagramResponse, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+
 	json.NewEncoder(w).Encode(resp)
 }
+
+func serveError(w http.ResponseWriter, err error) {
+	status, errMsg := handleError(err)
+	serveResponse(w, AnagramResponse{Error: errMsg}, status)
+}
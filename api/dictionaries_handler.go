@@ -0,0 +1,20 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
+)
+
+type DictionariesResponse struct {
+	Dictionaries []inputsource.Dictionary `json:"dictionaries"`
+}
+
+// ListDictionaries serves the dictionaries bundled with the server with their languages and word counts.
+func ListDictionaries(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+
+	json.NewEncoder(w).Encode(DictionariesResponse{Dictionaries: inputsource.Dictionaries()})
+}
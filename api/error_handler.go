@@ -1,8 +1,13 @@
 package api
 
 import (
+	"errors"
 	"log"
 	"net/http"
+	"strings"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
+	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
 )
 
 type HTTPError struct {
@@ -16,23 +21,151 @@ const (
 	ErrInvalidFile            = "failed to read file"
 	ErrUnsupportedContentType = "unsupported content type"
 	ErrInvalidInput           = "invalid input provided"
-	ErrInvalidInputType       = "invalid input type. supported types: http_body, http_file, http_url"
-	ErrInvalidAlgorithmType   = "invalid algorithm type. supported algorithms: basic"
-	ErrInvalidFileInput       = "input data should be empty for file input type"
+	ErrMissingInput           = "the request holds no file, url or input data"
+	ErrInvalidWord            = "invalid word"
+	ErrInvalidResponseFormat  = "invalid response format. supported formats: plain, detailed"
+	ErrInvalidCacheOption     = "invalid cache option. supported options: bypass"
+	ErrInvalidCompression     = "invalid compression. supported formats: plain, gzip, bzip2, zip, tar"
+	ErrInvalidArchive         = "the compressed input is corrupt"
+	ErrInvalidCharset         = "unsupported charset. supported charsets: utf-8, utf-16, utf-16le, utf-16be, iso-8859-1, windows-1252"
+	ErrInvalidEncoding        = "invalid encoding"
+	ErrInvalidCsvOptions      = "invalid csv options. the delimiter must be a single character or tab, the column a name or a positive index and a column name requires a header"
+	ErrInvalidCsv             = "the csv input is malformed"
+	ErrInvalidTokenizeOptions = "invalid tokenize options. dropPunctuation, dropNumbers and stripHtml require tokenize"
+	ErrCsvColumn              = "the csv column does not exist"
+	ErrBodyTooLarge           = "the request body exceeds the maximum size"
+	ErrDecompressionLimit     = "the decompressed input exceeds the size or compression ratio limit"
+	ErrFsDisabled             = "the fs input type is not enabled on this server"
+	ErrFsPath                 = "the path escapes the filesystem root"
+	ErrFsNotFound             = "no file matches the path"
+	ErrObjectStoreDisabled    = "the object_store input type is not enabled on this server"
+	ErrObjectStoreStatus      = "the object store responded with a non-2xx status"
+	ErrObjectNotFound         = "no object matches the key"
+	ErrObjectStoreBucket      = "the bucket is not allowed on this server"
+	ErrObjectStoreTimeout     = "the object store request timed out"
+	ErrObjectTooLarge         = "the object exceeds the maximum size"
+	ErrPrefixTooLarge         = "the prefix exceeds the maximum number of objects or total size"
+	ErrUnknownDictionary      = "unknown dictionary. GET /dictionaries lists the available dictionaries"
+	ErrUrlStatus              = "the url responded with a non-2xx status"
+	ErrUrlContentType         = "the url content type is not text"
+	ErrUrlTooLarge            = "the url content exceeds the maximum download size"
+	ErrUrlTimeout             = "the url download timed out"
+	ErrUrlBlocked             = "the url targets a blocked host or address"
+	ErrUrlRedirects           = "the url exceeded the maximum number of redirects"
+	ErrUrlChanged             = "the url content changed during the download"
+)
+
+// ErrInvalidInputType and ErrInvalidAlgorithmType list the registered input sources and algorithms.
+var (
+	ErrInvalidInputType     = "invalid input type. supported types: " + strings.Join(inputsource.SourceNames(), ", ")
+	ErrInvalidAlgorithmType = "invalid algorithm type. supported algorithms: " + strings.Join(anagram.AlgorithmNames(), ", ")
 )
 
 var ErrorMapping = map[string]HTTPError{
 	ErrInvalidInput:           {http.StatusBadRequest, ErrInvalidInput},
 	ErrInvalidInputType:       {http.StatusBadRequest, ErrInvalidInputType},
 	ErrInvalidAlgorithmType:   {http.StatusBadRequest, ErrInvalidAlgorithmType},
-	ErrInvalidFileInput:       {http.StatusBadRequest, ErrInvalidFileInput},
+	ErrMissingInput:           {http.StatusBadRequest, ErrMissingInput},
 	ErrInvalidFormat:          {http.StatusBadRequest, ErrInvalidFormat},
 	ErrInvalidFile:            {http.StatusBadRequest, ErrInvalidFile},
 	ErrUnsupportedContentType: {http.StatusBadRequest, ErrUnsupportedContentType},
+	ErrInvalidResponseFormat:  {http.StatusBadRequest, ErrInvalidResponseFormat},
+	ErrInvalidCacheOption:     {http.StatusBadRequest, ErrInvalidCacheOption},
+	ErrInvalidCompression:     {http.StatusBadRequest, ErrInvalidCompression},
+	ErrInvalidArchive:         {http.StatusBadRequest, ErrInvalidArchive},
+	ErrInvalidCharset:         {http.StatusBadRequest, ErrInvalidCharset},
+	ErrInvalidCsvOptions:      {http.StatusBadRequest, ErrInvalidCsvOptions},
+	ErrInvalidCsv:             {http.StatusBadRequest, ErrInvalidCsv},
+	ErrInvalidTokenizeOptions: {http.StatusBadRequest, ErrInvalidTokenizeOptions},
+	ErrCsvColumn:              {http.StatusBadRequest, ErrCsvColumn},
+	ErrBodyTooLarge:           {http.StatusRequestEntityTooLarge, ErrBodyTooLarge},
+	ErrDecompressionLimit:     {http.StatusRequestEntityTooLarge, ErrDecompressionLimit},
+	ErrFsDisabled:             {http.StatusBadRequest, ErrFsDisabled},
+	ErrFsPath:                 {http.StatusForbidden, ErrFsPath},
+	ErrFsNotFound:             {http.StatusNotFound, ErrFsNotFound},
+	ErrObjectStoreDisabled:    {http.StatusBadRequest, ErrObjectStoreDisabled},
+	ErrObjectStoreStatus:      {http.StatusBadGateway, ErrObjectStoreStatus},
+	ErrObjectNotFound:         {http.StatusNotFound, ErrObjectNotFound},
+	ErrObjectStoreBucket:      {http.StatusForbidden, ErrObjectStoreBucket},
+	ErrObjectStoreTimeout:     {http.StatusGatewayTimeout, ErrObjectStoreTimeout},
+	ErrObjectTooLarge:         {http.StatusRequestEntityTooLarge, ErrObjectTooLarge},
+	ErrPrefixTooLarge:         {http.StatusRequestEntityTooLarge, ErrPrefixTooLarge},
+	ErrUnknownDictionary:      {http.StatusNotFound, ErrUnknownDictionary},
+	ErrUrlStatus:              {http.StatusBadGateway, ErrUrlStatus},
+	ErrUrlContentType:         {http.StatusBadRequest, ErrUrlContentType},
+	ErrUrlTooLarge:            {http.StatusRequestEntityTooLarge, ErrUrlTooLarge},
+	ErrUrlTimeout:             {http.StatusGatewayTimeout, ErrUrlTimeout},
+	ErrUrlBlocked:             {http.StatusForbidden, ErrUrlBlocked},
+	ErrUrlRedirects:           {http.StatusBadGateway, ErrUrlRedirects},
+	ErrUrlChanged:             {http.StatusBadGateway, ErrUrlChanged},
+}
+
+// inputErrors maps errors of input sources, which occur while the input is streamed, to the error messages.
+var inputErrors = []struct {
+	target  error
+	message string
+}{
+	{inputsource.ErrUrlStatus, ErrUrlStatus},
+	{inputsource.ErrUrlContentType, ErrUrlContentType},
+	{inputsource.ErrUrlTooLarge, ErrUrlTooLarge},
+	{inputsource.ErrUrlTimeout, ErrUrlTimeout},
+	{inputsource.ErrUrlBlocked, ErrUrlBlocked},
+	{inputsource.ErrUrlRedirects, ErrUrlRedirects},
+	{inputsource.ErrUrlChanged, ErrUrlChanged},
+	{inputsource.ErrDecompressionLimit, ErrDecompressionLimit},
+	{inputsource.ErrInvalidArchive, ErrInvalidArchive},
+	{inputsource.ErrUnsupportedCharset, ErrInvalidCharset},
+	{inputsource.ErrInvalidCsv, ErrInvalidCsv},
+	{inputsource.ErrFsDisabled, ErrFsDisabled},
+	{inputsource.ErrFsPath, ErrFsPath},
+	{inputsource.ErrFsNotFound, ErrFsNotFound},
+	{inputsource.ErrObjectStoreDisabled, ErrObjectStoreDisabled},
+	{inputsource.ErrObjectStoreStatus, ErrObjectStoreStatus},
+	{inputsource.ErrObjectNotFound, ErrObjectNotFound},
+	{inputsource.ErrObjectStoreBucket, ErrObjectStoreBucket},
+	{inputsource.ErrObjectStoreTimeout, ErrObjectStoreTimeout},
+	{inputsource.ErrObjectTooLarge, ErrObjectTooLarge},
+	{inputsource.ErrPrefixTooLarge, ErrPrefixTooLarge},
+	{inputsource.ErrUnknownDictionary, ErrUnknownDictionary},
+	{inputsource.ErrCsvColumn, ErrCsvColumn},
+}
+
+func mapInputError(err error) error {
+	// request bodies are streamed by the http_text and ndjson input sources
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		log.Printf("input error: %v", err)
+		return errors.New(ErrBodyTooLarge)
+	}
+	for _, e := range inputErrors {
+		if errors.Is(err, e.target) {
+			log.Printf("input error: %v", err)
+			return errors.New(e.message)
+		}
+	}
+	return err
+}
+
+// formatError reports a request that could not be parsed, or whose body exceeds the maximum size.
+func formatError(err error) error {
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return errors.New(ErrBodyTooLarge)
+	}
+	return errors.New(ErrInvalidFormat)
 }
 
 func handleError(err error) (int, string) {
 	log.Printf("handler error: %v", err)
+	// invalid words and byte sequences are reported along with their position for the client to fix the input
+	var wordErr *inputsource.WordError
+	if errors.As(err, &wordErr) {
+		return http.StatusBadRequest, ErrInvalidWord + ": " + wordErr.Error()
+	}
+	var encodingErr *inputsource.EncodingError
+	if errors.As(err, &encodingErr) {
+		return http.StatusBadRequest, ErrInvalidEncoding + ": " + encodingErr.Error()
+	}
 	if httpErr, ok := ErrorMapping[err.Error()]; ok {
 		return httpErr.Code, httpErr.Message
 	}
@@ -0,0 +1,91 @@
+package api
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"os"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
+)
+
+// provenanceRecordSize is the size of a spooled provenance: the source index, the line and the column.
+const provenanceRecordSize = 4 + 8 + 4
+
+var errUnknownPosition = errors.New("no word at the position")
+
+// provenanceSpool records where every word of the input was read from in a temporary file, indexed by
+// the position of the word, so that the detailed response format does not hold the input in memory.
+// Only the source names are kept in memory, each once.
+type provenanceSpool struct {
+	file    *os.File
+	out     *bufio.Writer
+	sources map[string]uint32
+	names   []string
+	words   int
+	// flushed is set once the buffered records have been written out for reading.
+	flushed bool
+}
+
+func newProvenanceSpool() (*provenanceSpool, error) {
+	file, err := os.CreateTemp("", "anagram-provenance-")
+	if err != nil {
+		return nil, err
+	}
+
+	return &provenanceSpool{
+		file:    file,
+		out:     bufio.NewWriter(file),
+		sources: make(map[string]uint32),
+	}, nil
+}
+
+// add records the provenance of the word at the next position.
+func (ps *provenanceSpool) add(word inputsource.Word) error {
+	source, ok := ps.sources[word.Source]
+	if !ok {
+		source = uint32(len(ps.names))
+		ps.sources[word.Source] = source
+		ps.names = append(ps.names, word.Source)
+	}
+
+	var record [provenanceRecordSize]byte
+	binary.LittleEndian.PutUint32(record[0:], source)
+	binary.LittleEndian.PutUint64(record[4:], uint64(word.Line))
+	binary.LittleEndian.PutUint32(record[12:], uint32(word.Column))
+
+	ps.flushed = false
+	ps.words++
+	_, err := ps.out.Write(record[:])
+	return err
+}
+
+// lookup returns the provenance of the word at position.
+func (ps *provenanceSpool) lookup(position int) (WordProvenance, error) {
+	if position < 0 || position >= ps.words {
+		return WordProvenance{}, errUnknownPosition
+	}
+	if !ps.flushed {
+		if err := ps.out.Flush(); err != nil {
+			return WordProvenance{}, err
+		}
+		ps.flushed = true
+	}
+
+	var record [provenanceRecordSize]byte
+	if _, err := ps.file.ReadAt(record[:], int64(position)*provenanceRecordSize); err != nil {
+		return WordProvenance{}, err
+	}
+
+	return WordProvenance{
+		Source: ps.names[binary.LittleEndian.Uint32(record[0:])],
+		Line:   int(binary.LittleEndian.Uint64(record[4:])),
+		Column: int(binary.LittleEndian.Uint32(record[12:])),
+	}, nil
+}
+
+// close removes the spooled records.
+func (ps *provenanceSpool) close() {
+	ps.file.Close()
+	os.Remove(ps.file.Name())
+}
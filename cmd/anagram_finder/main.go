@@ -1,20 +1,67 @@
 package main
 
 import (
+	"log"
 	"net/http"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/onurdemirkale/anagram-finder/api"
 	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
 	"github.com/onurdemirkale/anagram-finder/pkg/inputsource"
 )
 
+const defaultUrlCacheSize = 128 * 1024 * 1024 // 128MB
+
 func main() {
-	var isf inputsource.InputSourceFactoryInterface = inputsource.NewInputSourceFactory()
-	aff := anagram.NewAnagramFinderFactory()
+	urlPolicy := inputsource.URLPolicy{
+		AllowedHosts: listEnv("ANAGRAM_URL_ALLOWED_HOSTS"),
+		DeniedHosts:  listEnv("ANAGRAM_URL_DENIED_HOSTS"),
+		MaxRedirects: intEnv("ANAGRAM_URL_MAX_REDIRECTS"),
+	}
+	if err := urlPolicy.Validate(); err != nil {
+		log.Fatalf("invalid url policy: %v", err)
+	}
+
+	var isf inputsource.InputSourceFactoryInterface = &inputsource.InputSourceFactory{
+		URLPolicy: urlPolicy,
+		URLCache:  urlCache(),
+		TempDir:   os.Getenv("ANAGRAM_TEMP_DIR"),
+		DecompressionLimits: inputsource.DecompressionLimits{
+			MaxSize:  int64(intEnv("ANAGRAM_MAX_DECOMPRESSED_SIZE")),
+			MaxRatio: int64(intEnv("ANAGRAM_MAX_COMPRESSION_RATIO")),
+		},
+		FSRoot: os.Getenv("ANAGRAM_FS_ROOT"),
+		ObjectStore: inputsource.ObjectStore{
+			Endpoint: os.Getenv("ANAGRAM_S3_ENDPOINT"),
+			Region:   os.Getenv("ANAGRAM_S3_REGION"),
+			Credentials: inputsource.Credentials{
+				AccessKeyID:     os.Getenv("ANAGRAM_S3_ACCESS_KEY_ID"),
+				SecretAccessKey: os.Getenv("ANAGRAM_S3_SECRET_ACCESS_KEY"),
+				SessionToken:    os.Getenv("ANAGRAM_S3_SESSION_TOKEN"),
+			},
+		},
+		ObjectStoreBuckets: listEnv("ANAGRAM_S3_BUCKETS"),
+	}
+	aff := &anagram.AnagramFinderFactory{
+		TempDir:      os.Getenv("ANAGRAM_TEMP_DIR"),
+		MemoryBudget: intEnv("ANAGRAM_MEMORY_BUDGET"),
+		MemoryLimit:  int64(intEnv("ANAGRAM_MEMORY_LIMIT")),
+	}
 	handler := api.NewAnagramHandler(isf, aff)
+	handler.MaxBodySize = int64(intEnv("ANAGRAM_MAX_BODY_SIZE"))
+
+	if dir := os.Getenv("ANAGRAM_DICTIONARY_DIR"); dir != "" {
+		if err := inputsource.RegisterDictionaries(os.DirFS(dir)); err != nil {
+			log.Fatalf("invalid value for ANAGRAM_DICTIONARY_DIR: %v", err)
+		}
+	}
 
 	http.HandleFunc("/healthz", healthCheckHandler)
 	http.HandleFunc("/anagram", handler.FindAnagrams)
+	http.HandleFunc("/algorithms", api.ListAlgorithms)
+	http.HandleFunc("/dictionaries", api.ListDictionaries)
 	http.ListenAndServe(":8080", nil)
 
 }
@@ -23,3 +70,53 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("OK"))
 }
+
+// urlCache returns the cache of URL inputs, stored in ANAGRAM_URL_CACHE_DIR when it is set and in memory otherwise.
+// ANAGRAM_URL_CACHE_SIZE is its size in bytes, a negative size disables the cache.
+func urlCache() inputsource.URLCache {
+	size := int64(intEnv("ANAGRAM_URL_CACHE_SIZE"))
+	if size < 0 {
+		return nil
+	}
+	if size == 0 {
+		size = defaultUrlCacheSize
+	}
+
+	dir := os.Getenv("ANAGRAM_URL_CACHE_DIR")
+	if dir == "" {
+		return inputsource.NewMemoryURLCache(size)
+	}
+
+	cache, err := inputsource.NewDiskURLCache(dir, size)
+	if err != nil {
+		log.Fatalf("invalid value for ANAGRAM_URL_CACHE_DIR: %v", err)
+	}
+
+	return cache
+}
+
+// listEnv returns the comma-separated values of the environment variable, nil if it is not set.
+func listEnv(key string) []string {
+	var values []string
+	for _, value := range strings.Split(os.Getenv(key), ",") {
+		if value = strings.TrimSpace(value); value != "" {
+			values = append(values, value)
+		}
+	}
+	return values
+}
+
+// intEnv returns the integer value of the environment variable, 0 if it is not set.
+func intEnv(key string) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return 0
+	}
+
+	i, err := strconv.Atoi(value)
+	if err != nil {
+		log.Fatalf("invalid value for %s: %v", key, err)
+	}
+
+	return i
+}
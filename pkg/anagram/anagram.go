@@ -1,5 +1,76 @@
 package anagram
 
+import "sort"
+
 type AnagramFinder interface {
 	FindAnagrams(words []string) ([][]string, error)
 }
+
+// DetailedAnagramFinder is implemented by finders that can report the key behind each group.
+type DetailedAnagramFinder interface {
+	FindAnagramGroups(words []string) ([]AnagramGroup, error)
+}
+
+// AnagramGroup is a group of anagrams together with its canonical signature,
+// the letter histogram of the signature and the input positions of its members.
+type AnagramGroup struct {
+	Signature string
+	Histogram map[string]int
+	Words     []string
+	Positions []int
+}
+
+// FindAnagramGroups finds the anagram groups among the words using the given finder.
+// Finders that do not implement DetailedAnagramFinder are wrapped, the signature
+// and positions of their groups are derived from the input words.
+func FindAnagramGroups(finder AnagramFinder, words []string) ([]AnagramGroup, error) {
+	if df, ok := finder.(DetailedAnagramFinder); ok {
+		return df.FindAnagramGroups(words)
+	}
+
+	anagramGroups, err := finder.FindAnagrams(words)
+	if err != nil {
+		return nil, err
+	}
+
+	positions := make(map[string][]int)
+	for i, word := range words {
+		positions[word] = append(positions[word], i)
+	}
+
+	groups := make([]AnagramGroup, 0, len(anagramGroups))
+	for _, anagramGroup := range anagramGroups {
+		group := newAnagramGroup(Signature(anagramGroup[0]))
+		for _, word := range anagramGroup {
+			group.Words = append(group.Words, word)
+			group.Positions = append(group.Positions, positions[word][0])
+			positions[word] = positions[word][1:]
+		}
+		groups = append(groups, group)
+	}
+
+	sortAnagramGroups(groups)
+
+	return groups, nil
+}
+
+func newAnagramGroup(signature string) AnagramGroup {
+	return AnagramGroup{
+		Signature: signature,
+		Histogram: letterHistogram(signature),
+	}
+}
+
+// letterHistogram counts the occurrences of each letter in the signature.
+func letterHistogram(signature string) map[string]int {
+	histogram := make(map[string]int)
+	for _, r := range signature {
+		histogram[string(r)]++
+	}
+	return histogram
+}
+
+// sortAnagramGroups orders the groups by signature so that results are stable across runs.
+func sortAnagramGroups(groups []AnagramGroup) {
+	sort.Slice(groups, func(i, j int) bool { return groups[i].Signature < groups[j].Signature })
+}
@@ -1 +1,43 @@
 package anagram
+
+import (
+	"reflect"
+	"testing"
+)
+
+type plainAnagramFinder struct {
+	groups [][]string
+}
+
+func (p *plainAnagramFinder) FindAnagrams(words []string) ([][]string, error) {
+	return p.groups, nil
+}
+
+func TestFindAnagramGroups_PlainFinder(t *testing.T) {
+	words := []string{"tac", "dog", "cat", "god", "cat"}
+	finder := &plainAnagramFinder{groups: [][]string{{"dog", "god"}, {"tac", "cat", "cat"}}}
+
+	expected := []AnagramGroup{
+		{
+			Signature: "act",
+			Histogram: map[string]int{"a": 1, "c": 1, "t": 1},
+			Words:     []string{"tac", "cat", "cat"},
+			Positions: []int{0, 2, 4},
+		},
+		{
+			Signature: "dgo",
+			Histogram: map[string]int{"d": 1, "g": 1, "o": 1},
+			Words:     []string{"dog", "god"},
+			Positions: []int{1, 3},
+		},
+	}
+
+	actual, err := FindAnagramGroups(finder, words)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
@@ -0,0 +1,304 @@
+package anagram
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"unicode/utf8"
+)
+
+const (
+	// autoSampleSize is the number of words profiled before an algorithm is selected.
+	autoSampleSize = 10000
+	pipeBufferSize = 1024
+)
+
+// SelectingAnagramFinder is implemented by finders delegating to an algorithm selected at runtime.
+type SelectingAnagramFinder interface {
+	SelectedAlgorithm() string
+}
+
+// SizeHinter is implemented by finders using the size of the input in bytes, when it is known up front.
+type SizeHinter interface {
+	SetSizeHint(size int64)
+}
+
+// AutoAnagramFinder implements the AnagramFinder interface by delegating to the algorithm best suited to the input.
+// The first words of the input are profiled for their count, length spread and character set, the memory and
+// time needed by each in-memory algorithm are then estimated from their benchmarked costs. The fastest algorithm
+// fitting into the memory limit is selected, external_sort is used when none of them does or when the size of
+// the input is unknown.
+type AutoAnagramFinder struct {
+	factory     *AnagramFinderFactory
+	calibration *calibration
+	sizeHint    int64
+	selected    string
+}
+
+func init() {
+	Register(Algorithm{
+		Name:         "auto",
+		Description:  "Selects the fastest algorithm fitting into the memory limit from a sample of the input.",
+		Capabilities: Capabilities{Automatic: true},
+		Options: []Option{
+			{Name: "memoryLimit", Type: "integer", Env: "ANAGRAM_MEMORY_LIMIT", Default: strconv.Itoa(defaultMemoryLimit), Description: "Bytes in-memory algorithms may use before external_sort is selected."},
+		},
+		New: func(f *AnagramFinderFactory) AnagramFinder { return NewAutoAnagramFinder(f) },
+	})
+}
+
+func NewAutoAnagramFinder(factory *AnagramFinderFactory) *AutoAnagramFinder {
+	return &AutoAnagramFinder{factory: factory}
+}
+
+func (a *AutoAnagramFinder) SetSizeHint(size int64) {
+	a.sizeHint = size
+}
+
+// SelectedAlgorithm returns the algorithm used by the last search.
+func (a *AutoAnagramFinder) SelectedAlgorithm() string {
+	return a.selected
+}
+
+func (a *AutoAnagramFinder) FindAnagrams(words []string) ([][]string, error) {
+	return a.findAnagrams(context.Background(), SliceWordStream(words), sliceSize(words))
+}
+
+func (a *AutoAnagramFinder) FindAnagramsStream(ctx context.Context, stream WordStream) ([][]string, error) {
+	return a.findAnagrams(ctx, stream, a.sizeHint)
+}
+
+func (a *AutoAnagramFinder) findAnagrams(ctx context.Context, stream WordStream, sizeHint int64) ([][]string, error) {
+	finder, replay, done, err := a.selectFinder(ctx, stream, sizeHint)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return StreamAnagrams(ctx, finder, replay)
+}
+
+func (a *AutoAnagramFinder) FindAnagramGroups(words []string) ([]AnagramGroup, error) {
+	return a.findAnagramGroups(context.Background(), SliceWordStream(words), sliceSize(words))
+}
+
+func (a *AutoAnagramFinder) FindAnagramGroupsStream(ctx context.Context, stream WordStream) ([]AnagramGroup, error) {
+	return a.findAnagramGroups(ctx, stream, a.sizeHint)
+}
+
+func (a *AutoAnagramFinder) findAnagramGroups(ctx context.Context, stream WordStream, sizeHint int64) ([]AnagramGroup, error) {
+	finder, replay, done, err := a.selectFinder(ctx, stream, sizeHint)
+	if err != nil {
+		return nil, err
+	}
+	defer done()
+
+	return StreamAnagramGroups(ctx, finder, replay)
+}
+
+// EmitAnagramGroups passes the groups of the selected finder to fn, as they are found when it implements
+// EmittingAnagramFinder and once all of them have been found otherwise.
+func (a *AutoAnagramFinder) EmitAnagramGroups(ctx context.Context, stream WordStream, fn func(group AnagramGroup) error) error {
+	finder, replay, done, err := a.selectFinder(ctx, stream, a.sizeHint)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if ef, ok := finder.(EmittingAnagramFinder); ok {
+		return ef.EmitAnagramGroups(ctx, replay, fn)
+	}
+
+	groups, err := StreamAnagramGroups(ctx, finder, replay)
+	if err != nil {
+		return err
+	}
+	for _, group := range groups {
+		if err := fn(group); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// sliceSize returns the size of words read as lines, the size of an input given as a slice.
+func sliceSize(words []string) int64 {
+	var size int64
+	for _, word := range words {
+		size += int64(len(word)) + 1
+	}
+	return size
+}
+
+// selectFinder profiles the first words of the stream and creates the selected finder.
+// The returned stream replays the profiled words before the rest of the input.
+// done must be called once the returned stream is no longer used.
+func (a *AutoAnagramFinder) selectFinder(ctx context.Context, stream WordStream, sizeHint int64) (AnagramFinder, WordStream, func(), error) {
+	pipe := newStreamPipe(ctx, stream)
+
+	profile := inputProfile{sizeHint: sizeHint}
+	var sample []pipedWord
+
+	for len(sample) < autoSampleSize {
+		word, ok, err := pipe.next()
+		if err != nil {
+			pipe.close()
+			return nil, nil, nil, err
+		}
+		if !ok {
+			profile.exhausted = true
+			break
+		}
+
+		sample = append(sample, word)
+		profile.add(word.word)
+	}
+
+	calibration := a.calibration
+	if calibration == nil {
+		calibration = defaultCalibration
+	}
+
+	a.selected = calibration.choose(profile, a.factory.memoryLimit())
+
+	finder, err := a.factory.CreateAnagramFinder(a.selected)
+	if err != nil {
+		pipe.close()
+		return nil, nil, nil, err
+	}
+
+	replay := func(yield func(word, signature string) error) error {
+		for _, word := range sample {
+			if err := yield(word.word, word.signature); err != nil {
+				return err
+			}
+		}
+		sample = nil
+
+		for {
+			word, ok, err := pipe.next()
+			if err != nil || !ok {
+				return err
+			}
+			if err := yield(word.word, word.signature); err != nil {
+				return err
+			}
+		}
+	}
+
+	return finder, replay, pipe.close, nil
+}
+
+// inputProfile describes the words sampled from an input.
+type inputProfile struct {
+	words     int
+	bytes     int64
+	exhausted bool
+	sizeHint  int64
+	maxLen    int
+	meanLen   float64
+	// m2 is the sum of squared differences from the mean length
+	m2    float64
+	ascii bool
+}
+
+func (p *inputProfile) add(word string) {
+	if p.words == 0 {
+		p.ascii = true
+	}
+
+	length := utf8.RuneCountInString(word)
+	p.words++
+	p.bytes += int64(len(word)) + 1
+
+	delta := float64(length) - p.meanLen
+	p.meanLen += delta / float64(p.words)
+	p.m2 += delta * (float64(length) - p.meanLen)
+
+	if length > p.maxLen {
+		p.maxLen = length
+	}
+	if p.ascii && len(word) != length {
+		p.ascii = false
+	}
+}
+
+func (p *inputProfile) stddevLen() float64 {
+	if p.words < 2 {
+		return 0
+	}
+	return math.Sqrt(p.m2 / float64(p.words-1))
+}
+
+// estimatedWords returns the expected number of words of the whole input, -1 when it is unknown.
+func (p *inputProfile) estimatedWords() float64 {
+	if p.exhausted {
+		return float64(p.words)
+	}
+	if p.sizeHint <= 0 || p.words == 0 {
+		return -1
+	}
+
+	estimate := float64(p.sizeHint) / (float64(p.bytes) / float64(p.words))
+
+	return math.Max(estimate, float64(p.words))
+}
+
+type pipedWord struct {
+	word      string
+	signature string
+}
+
+// streamPipe runs a stream on its own goroutine so that its words can be pulled one at a time.
+type streamPipe struct {
+	words  chan pipedWord
+	errc   chan error
+	err    error
+	cancel context.CancelFunc
+}
+
+func newStreamPipe(ctx context.Context, stream WordStream) *streamPipe {
+	ctx, cancel := context.WithCancel(ctx)
+
+	p := &streamPipe{
+		words:  make(chan pipedWord, pipeBufferSize),
+		errc:   make(chan error, 1),
+		cancel: cancel,
+	}
+
+	go func() {
+		defer close(p.words)
+		p.errc <- stream(func(word, signature string) error {
+			select {
+			case p.words <- pipedWord{word: word, signature: signature}:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return p
+}
+
+// next returns the next word of the stream, ok is false once the stream has ended.
+func (p *streamPipe) next() (word pipedWord, ok bool, err error) {
+	if word, ok := <-p.words; ok {
+		return word, true, nil
+	}
+
+	if p.errc != nil {
+		p.err = <-p.errc
+		p.errc = nil
+	}
+
+	return pipedWord{}, false, p.err
+}
+
+// close stops the stream and waits for its goroutine to finish.
+func (p *streamPipe) close() {
+	p.cancel()
+	for range p.words {
+	}
+}
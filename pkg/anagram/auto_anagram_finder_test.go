@@ -0,0 +1,245 @@
+package anagram
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"sort"
+	"testing"
+	"time"
+)
+
+func TestCalibration_Choose(t *testing.T) {
+	c := &calibration{costs: map[string]algorithmCost{
+		"sort_map": {baseBytes: 100, bytesPerLetter: 2, asciiNsPerWord: 200, unicodeNsPerWord: 900},
+		"compact":  {baseBytes: 30, bytesPerLetter: 1, asciiNsPerWord: 300, unicodeNsPerWord: 800},
+	}}
+
+	testCases := []struct {
+		name        string
+		profile     inputProfile
+		memoryLimit int64
+		expected    string
+	}{
+		{
+			name:        "small ascii input uses the fastest algorithm",
+			profile:     inputProfile{words: 1000, bytes: 7000, exhausted: true, meanLen: 6, ascii: true},
+			memoryLimit: 1 << 20,
+			expected:    "sort_map",
+		},
+		{
+			name:        "small unicode input uses the fastest algorithm for unicode",
+			profile:     inputProfile{words: 1000, bytes: 13000, exhausted: true, meanLen: 6},
+			memoryLimit: 1 << 20,
+			expected:    "compact",
+		},
+		{
+			name:        "input fitting only the compact store",
+			profile:     inputProfile{words: 10000, bytes: 70000, sizeHint: 7000000, meanLen: 6, ascii: true},
+			memoryLimit: 64 << 20,
+			expected:    "compact",
+		},
+		{
+			name:        "input larger than the memory limit",
+			profile:     inputProfile{words: 10000, bytes: 70000, sizeHint: 70000000, meanLen: 6, ascii: true},
+			memoryLimit: 64 << 20,
+			expected:    "external_sort",
+		},
+		{
+			name:        "unknown input size",
+			profile:     inputProfile{words: 10000, bytes: 70000, meanLen: 6, ascii: true},
+			memoryLimit: 64 << 20,
+			expected:    "external_sort",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := c.choose(tc.profile, tc.memoryLimit)
+
+			if actual != tc.expected {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestAlgorithmCosts(t *testing.T) {
+	for _, a := range Algorithms() {
+		if _, ok := a.New(&AnagramFinderFactory{}).(StreamingAnagramFinder); !ok || !a.Capabilities.InMemory {
+			continue
+		}
+		if _, ok := benchmarkedCosts[a.Name]; !ok {
+			t.Errorf("in-memory algorithm %s has no benchmarked costs", a.Name)
+		}
+	}
+
+	if _, ok := algorithmCosts["sort_map"]; ok {
+		t.Errorf("expected sort_map, dominated by compact, to be left out of the selection")
+	}
+}
+
+func TestSelectableCosts(t *testing.T) {
+	costs := selectableCosts(map[string]algorithmCost{
+		"fast":      {baseBytes: 50, bytesPerLetter: 1, asciiNsPerWord: 100, unicodeNsPerWord: 200},
+		"small":     {baseBytes: 20, bytesPerLetter: 1, asciiNsPerWord: 300, unicodeNsPerWord: 400},
+		"dominated": {baseBytes: 60, bytesPerLetter: 1, asciiNsPerWord: 300, unicodeNsPerWord: 400},
+	})
+
+	var names []string
+	for name := range costs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	if expected := []string{"fast", "small"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected selectable algorithms %v, got %v", expected, names)
+	}
+}
+
+// every algorithm of the selection is chosen for some input with the benchmarked costs
+func TestDefaultCalibration_ChoosesEveryCandidate(t *testing.T) {
+	profiles := []inputProfile{
+		{words: 1000, bytes: 7000, exhausted: true, meanLen: 6, ascii: true},
+		{words: 1000, bytes: 13000, exhausted: true, meanLen: 6},
+		{words: 10000, bytes: 70000, sizeHint: 7000000000, meanLen: 6, ascii: true},
+		{words: 10000, bytes: 70000, meanLen: 6, ascii: true},
+	}
+
+	chosen := map[string]bool{}
+	for _, p := range profiles {
+		chosen[defaultCalibration.choose(p, defaultMemoryLimit)] = true
+	}
+
+	for name := range algorithmCosts {
+		if !chosen[name] {
+			t.Errorf("expected %s to be chosen for one of the inputs, chose %v", name, chosen)
+		}
+	}
+	if !chosen["external_sort"] {
+		t.Errorf("expected external_sort to be chosen for one of the inputs, chose %v", chosen)
+	}
+}
+
+func TestAutoAnagramFinder_FindAnagramGroups(t *testing.T) {
+	var words []string
+	for i := 0; i < 3*autoSampleSize; i++ {
+		words = append(words, fmt.Sprintf("w%05d", i), fmt.Sprintf("%05dw", i))
+	}
+
+	expected, err := NewSortMapAnagramFinder().FindAnagramGroups(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, algorithm := range []string{"sort_map", "compact", "external_sort"} {
+		t.Run(algorithm, func(t *testing.T) {
+			finder := NewAutoAnagramFinder(&AnagramFinderFactory{TempDir: t.TempDir()})
+			finder.calibration = &calibration{costs: map[string]algorithmCost{}}
+			if algorithm != "external_sort" {
+				finder.calibration.costs[algorithm] = algorithmCost{baseBytes: 1}
+			}
+
+			actual, err := finder.FindAnagramGroups(words)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if finder.SelectedAlgorithm() != algorithm {
+				t.Errorf("Expected %v to be selected, got %v", algorithm, finder.SelectedAlgorithm())
+			}
+
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Expected %d groups matching sort_map, got %d", len(expected), len(actual))
+			}
+		})
+	}
+}
+
+func TestAutoAnagramFinder_StreamError(t *testing.T) {
+	streamErr := fmt.Errorf("read failed")
+	stream := func(yield func(word, signature string) error) error {
+		for i := 0; i < autoSampleSize/2; i++ {
+			if err := yield("cat", ""); err != nil {
+				return err
+			}
+		}
+		return streamErr
+	}
+
+	finder := NewAutoAnagramFinder(&AnagramFinderFactory{})
+	finder.calibration = &calibration{costs: map[string]algorithmCost{"sort_map": {}}}
+
+	_, err := finder.FindAnagramsStream(context.Background(), stream)
+	if err != streamErr {
+		t.Errorf("Expected %v, got %v", streamErr, err)
+	}
+}
+
+// BenchmarkAutoSelectionCosts measures the memory and time each in-memory algorithm needs per word, which
+// are the costs the automatic selection uses. Run it with -benchtime 5x and update algorithmCosts with the
+// B/word of short and long ASCII words and the ns/word of short ASCII and Unicode words.
+func BenchmarkAutoSelectionCosts(b *testing.B) {
+	const words = 100000
+
+	inputs := []struct {
+		name     string
+		length   int
+		alphabet string
+	}{
+		{"ascii-6", 6, "abcdefghijklmnopqrstuvwxyz"},
+		{"ascii-16", 16, "abcdefghijklmnopqrstuvwxyz"},
+		{"unicode-6", 6, "äöüßéèêàçñåøæłşğıžčřőűабвгдеж"},
+	}
+
+	for _, a := range Algorithms() {
+		if !a.Capabilities.InMemory {
+			continue
+		}
+		if _, ok := a.New(&AnagramFinderFactory{}).(StreamingAnagramFinder); !ok {
+			continue
+		}
+
+		for _, input := range inputs {
+			b.Run(a.Name+"/"+input.name, func(b *testing.B) {
+				letters := []rune(input.alphabet)
+				var heapBytes, elapsed float64
+
+				for i := 0; i < b.N; i++ {
+					finder := a.New(&AnagramFinderFactory{}).(StreamingAnagramFinder)
+					rng := rand.New(rand.NewSource(1))
+					word := make([]rune, input.length)
+
+					var before, after runtime.MemStats
+					runtime.GC()
+					runtime.ReadMemStats(&before)
+					start := time.Now()
+
+					// the heap is measured once the last word has been streamed, while the finder holds all of its state
+					finder.FindAnagramsStream(context.Background(), func(yield func(word, signature string) error) error {
+						for j := 0; j < words; j++ {
+							for k := range word {
+								word[k] = letters[rng.Intn(len(letters))]
+							}
+							if err := yield(string(word), ""); err != nil {
+								return err
+							}
+						}
+
+						elapsed += float64(time.Since(start).Nanoseconds())
+						runtime.GC()
+						runtime.ReadMemStats(&after)
+						heapBytes += float64(after.HeapAlloc) - float64(before.HeapAlloc)
+
+						return nil
+					})
+				}
+
+				b.ReportMetric(heapBytes/float64(b.N)/words, "B/word")
+				b.ReportMetric(elapsed/float64(b.N)/words, "ns/word")
+			})
+		}
+	}
+}
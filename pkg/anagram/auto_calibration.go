@@ -0,0 +1,109 @@
+package anagram
+
+import (
+	"math"
+)
+
+// benchmarkedCosts are the costs of the in-memory algorithms measured by BenchmarkAutoSelectionCosts.
+// Fixed costs keep the selection the same from one run of the server to the next.
+var benchmarkedCosts = map[string]algorithmCost{
+	"compact":  {baseBytes: 25, bytesPerLetter: 1.05, asciiNsPerWord: 520, unicodeNsPerWord: 730},
+	"sort_map": {baseBytes: 55, bytesPerLetter: 0.8, asciiNsPerWord: 960, unicodeNsPerWord: 1670},
+}
+
+// maxTypicalWordLength bounds the word lengths the selection is tuned for, longer words are rare in any language.
+const maxTypicalWordLength = 64
+
+// algorithmCosts are the costs of the algorithms the automatic selection chooses from. Algorithms measured to
+// be slower and larger than another one for every word of up to maxTypicalWordLength letters would never be
+// chosen and are left out. With the current measurements this leaves out sort_map, which only uses less memory
+// than compact for words of more than 120 letters.
+var algorithmCosts = selectableCosts(benchmarkedCosts)
+
+// selectableCosts returns the costs without the algorithms dominated by another one.
+func selectableCosts(costs map[string]algorithmCost) map[string]algorithmCost {
+	selectable := make(map[string]algorithmCost, len(costs))
+	for name, cost := range costs {
+		dominated := false
+		for other, otherCost := range costs {
+			if other != name && otherCost.dominates(cost) {
+				dominated = true
+				break
+			}
+		}
+		if !dominated {
+			selectable[name] = cost
+		}
+	}
+	return selectable
+}
+
+var defaultCalibration = &calibration{costs: algorithmCosts}
+
+// algorithmCost is the cost of an algorithm. The memory used per word grows linearly with the
+// length of the word, from baseBytes by bytesPerLetter.
+type algorithmCost struct {
+	baseBytes        float64
+	bytesPerLetter   float64
+	asciiNsPerWord   float64
+	unicodeNsPerWord float64
+}
+
+func (c algorithmCost) bytesPerWord(length float64) float64 {
+	return c.baseBytes + c.bytesPerLetter*length
+}
+
+// dominates reports whether c is at least as fast as other for any charset and needs at most as much memory
+// for words of up to maxTypicalWordLength letters, being better in one of them. As the memory grows linearly,
+// comparing the shortest and longest words covers the lengths between them.
+func (c algorithmCost) dominates(other algorithmCost) bool {
+	if c.asciiNsPerWord > other.asciiNsPerWord || c.unicodeNsPerWord > other.unicodeNsPerWord {
+		return false
+	}
+	for _, length := range []float64{0, maxTypicalWordLength} {
+		if c.bytesPerWord(length) > other.bytesPerWord(length) {
+			return false
+		}
+	}
+	return c != other
+}
+
+type calibration struct {
+	costs map[string]algorithmCost
+}
+
+// choose returns the fastest in-memory algorithm whose estimated memory use fits into the limit.
+// Word lengths are estimated one standard deviation above the mean to account for their spread.
+// When the size of the input is unknown external_sort is chosen, as the memory used by the
+// in-memory algorithms cannot be bounded.
+func (c *calibration) choose(p inputProfile, memoryLimit int64) string {
+	words := p.estimatedWords()
+	if words < 0 {
+		return "external_sort"
+	}
+	length := p.meanLen + p.stddevLen()
+
+	best := ""
+	bestScore := math.Inf(1)
+
+	for name, cost := range c.costs {
+		if words*cost.bytesPerWord(length) > float64(memoryLimit) {
+			continue
+		}
+
+		score := cost.asciiNsPerWord
+		if !p.ascii {
+			score = cost.unicodeNsPerWord
+		}
+
+		if score < bestScore || (score == bestScore && name < best) {
+			best, bestScore = name, score
+		}
+	}
+
+	if best == "" {
+		return "external_sort"
+	}
+
+	return best
+}
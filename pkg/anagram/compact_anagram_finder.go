@@ -0,0 +1,303 @@
+package anagram
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"math"
+	"sort"
+	"strings"
+)
+
+var ErrCompactStoreFull = errors.New("compact store cannot hold more than 4GB or 2^31 words")
+
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+// CompactAnagramFinder implements the AnagramFinder interface with a compact group store for large inputs.
+// Words are kept in a single byte arena addressed by offsets and groups are indexed by a 64-bit hash of
+// their signature instead of the signature string. A word costs its length plus 8 bytes and each group
+// about 16 bytes, compared to the string headers, signature strings and map entries of the sort & map approach.
+type CompactAnagramFinder struct{}
+
+func init() {
+	Register(Algorithm{
+		Name:         "compact",
+		Description:  "Groups words like sort_map in a byte arena indexed by signature hashes, using a fraction of its memory.",
+		Capabilities: Capabilities{InMemory: true},
+		New:          func(*AnagramFinderFactory) AnagramFinder { return NewCompactAnagramFinder() },
+	})
+}
+
+func NewCompactAnagramFinder() *CompactAnagramFinder {
+	return &CompactAnagramFinder{}
+}
+
+// Finds anagrams among the words provided.
+// Time complexity: O(N*M*log(M)) where N is the number of words and M is the maximum length of a word.
+// Space complexity: O(N*M) with a small constant per word.
+func (c *CompactAnagramFinder) FindAnagrams(words []string) ([][]string, error) {
+	return c.FindAnagramsStream(context.Background(), SliceWordStream(words))
+}
+
+func (c *CompactAnagramFinder) FindAnagramsStream(ctx context.Context, stream WordStream) ([][]string, error) {
+	store, err := c.buildStore(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := store.groupsOf()
+	result := make([][]string, 0, len(groups))
+
+	for _, group := range groups {
+		result = append(result, group.Words)
+	}
+
+	return result, nil
+}
+
+func (c *CompactAnagramFinder) FindAnagramGroups(words []string) ([]AnagramGroup, error) {
+	return c.FindAnagramGroupsStream(context.Background(), SliceWordStream(words))
+}
+
+func (c *CompactAnagramFinder) FindAnagramGroupsStream(ctx context.Context, stream WordStream) ([]AnagramGroup, error) {
+	store, err := c.buildStore(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := store.groupsOf()
+	for i := range groups {
+		groups[i].Histogram = letterHistogram(groups[i].Signature)
+	}
+
+	sortAnagramGroups(groups)
+
+	return groups, nil
+}
+
+func (c *CompactAnagramFinder) buildStore(stream WordStream) (*compactStore, error) {
+	store := newCompactStore(hashSignature)
+	builder := NewSignatureBuilder(false)
+	var key []byte
+
+	err := stream(func(word, signature string) error {
+		if signature != "" {
+			key = append(key[:0], signature...)
+		} else {
+			key = builder.AppendSignature(key[:0], word)
+		}
+		return store.add(word, key)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// hashSignature is the 64-bit FNV-1a hash of the signature.
+func hashSignature(signature []byte) uint64 {
+	h := uint64(fnvOffset64)
+	for _, c := range signature {
+		h ^= uint64(c)
+		h *= fnvPrime64
+	}
+	return h
+}
+
+// compactStore groups words by signature while keeping all words in one byte arena.
+// The words of a group are linked from the last one to the first one, groups are found through
+// the hash of their signature. Signatures are not stored, every hash hit is verified by computing
+// the signature of the group's last word. Signatures colliding with another one are indexed by
+// their full value.
+type compactStore struct {
+	hash    func(signature []byte) uint64
+	builder *SignatureBuilder
+	scratch []byte
+
+	// the i-th word is arena[offsets[i]:offsets[i+1]]
+	arena   []byte
+	offsets []uint32
+	// previous links each word to the previous word of its group, -1 ends the group
+	previous []int32
+
+	index      compactIndex
+	collisions map[string]int32
+}
+
+func newCompactStore(hash func(signature []byte) uint64) *compactStore {
+	return &compactStore{
+		hash:    hash,
+		builder: NewSignatureBuilder(false),
+		offsets: []uint32{0},
+	}
+}
+
+func (s *compactStore) add(word string, signature []byte) error {
+	if uint64(len(s.arena)+len(word)) > math.MaxUint32 || len(s.previous) == math.MaxInt32 {
+		return ErrCompactStoreFull
+	}
+
+	position := int32(len(s.previous))
+	s.arena = append(s.arena, word...)
+	s.offsets = append(s.offsets, uint32(len(s.arena)))
+	s.previous = append(s.previous, -1)
+
+	h := s.hash(signature)
+	last, ok := s.index.get(h)
+
+	if ok && !bytes.Equal(s.signatureOf(last), signature) {
+		if s.collisions == nil {
+			s.collisions = make(map[string]int32)
+		}
+		if last, ok := s.collisions[string(signature)]; ok {
+			s.previous[position] = last
+		}
+		s.collisions[string(signature)] = position
+		return nil
+	}
+
+	if ok {
+		s.previous[position] = last
+	}
+	s.index.put(h, position)
+
+	return nil
+}
+
+func (s *compactStore) word(i int32) []byte {
+	return s.arena[s.offsets[i]:s.offsets[i+1]]
+}
+
+func (s *compactStore) signatureOf(i int32) []byte {
+	s.scratch = appendSignature(s.builder, s.scratch[:0], s.word(i))
+	return s.scratch
+}
+
+// groupsOf returns the groups with at least two words in the order of their first word.
+// The words of all groups share a single string holding only the grouped words.
+func (s *compactStore) groupsOf() []AnagramGroup {
+	var lasts []int32
+	s.index.each(func(last int32) {
+		if s.previous[last] >= 0 {
+			lasts = append(lasts, last)
+		}
+	})
+	for _, last := range s.collisions {
+		if s.previous[last] >= 0 {
+			lasts = append(lasts, last)
+		}
+	}
+
+	size := 0
+	for _, last := range lasts {
+		for i := last; i >= 0; i = s.previous[i] {
+			size += len(s.word(i))
+		}
+	}
+
+	var sb strings.Builder
+	sb.Grow(size)
+
+	type span struct{ start, end int }
+	spans := make([][]span, len(lasts))
+	result := make([]AnagramGroup, len(lasts))
+
+	for g, last := range lasts {
+		for i := last; i >= 0; i = s.previous[i] {
+			start := sb.Len()
+			sb.Write(s.word(i))
+			spans[g] = append(spans[g], span{start, sb.Len()})
+			result[g].Positions = append(result[g].Positions, int(i))
+		}
+		result[g].Signature = string(s.signatureOf(last))
+	}
+
+	words := sb.String()
+	for g := range result {
+		// the words were collected from the last one, restore the input order
+		n := len(spans[g])
+		result[g].Words = make([]string, n)
+		for i, sp := range spans[g] {
+			result[g].Words[n-1-i] = words[sp.start:sp.end]
+		}
+		positions := result[g].Positions
+		for i, j := 0, n-1; i < j; i, j = i+1, j-1 {
+			positions[i], positions[j] = positions[j], positions[i]
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Positions[0] < result[j].Positions[0] })
+
+	return result
+}
+
+// compactIndex is an open addressing hash table from signature hashes to word positions.
+// An entry takes 12 bytes and the table is kept at most 80% full.
+type compactIndex struct {
+	hashes []uint64
+	// values holds the position plus one, zero marks an empty slot
+	values []int32
+	count  int
+}
+
+func (x *compactIndex) slot(h uint64) int {
+	mask := len(x.hashes) - 1
+	i := int((h ^ h>>32) & uint64(mask))
+	for x.values[i] != 0 && x.hashes[i] != h {
+		i = (i + 1) & mask
+	}
+	return i
+}
+
+func (x *compactIndex) get(h uint64) (int32, bool) {
+	if x.count == 0 {
+		return 0, false
+	}
+
+	i := x.slot(h)
+	return x.values[i] - 1, x.values[i] != 0
+}
+
+func (x *compactIndex) put(h uint64, position int32) {
+	if (x.count+1)*5 > len(x.hashes)*4 {
+		x.grow()
+	}
+
+	i := x.slot(h)
+	if x.values[i] == 0 {
+		x.count++
+	}
+	x.hashes[i] = h
+	x.values[i] = position + 1
+}
+
+func (x *compactIndex) grow() {
+	hashes, values := x.hashes, x.values
+
+	size := 2 * len(hashes)
+	if size == 0 {
+		size = 64
+	}
+	x.hashes = make([]uint64, size)
+	x.values = make([]int32, size)
+
+	for i, value := range values {
+		if value != 0 {
+			j := x.slot(hashes[i])
+			x.hashes[j] = hashes[i]
+			x.values[j] = value
+		}
+	}
+}
+
+func (x *compactIndex) each(fn func(position int32)) {
+	for _, value := range x.values {
+		if value != 0 {
+			fn(value - 1)
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package anagram
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"runtime"
+	"testing"
+)
+
+func TestCompactAnagramFinder_FindAnagrams(t *testing.T) {
+	testCases := []struct {
+		name     string
+		words    []string
+		expected [][]string
+	}{
+		{
+			name:     "no words",
+			words:    []string{},
+			expected: [][]string{},
+		},
+		{
+			name:     "multiple anagrams",
+			words:    []string{"cat", "dog", "tac", "god", "good", "act"},
+			expected: [][]string{{"cat", "tac", "act"}, {"dog", "god"}},
+		},
+		{
+			name:     "multi-word and unicode anagrams",
+			words:    []string{"debit card", "Ärger", "bad credit", "Regär"},
+			expected: [][]string{{"debit card", "bad credit"}, {"Ärger", "Regär"}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := NewCompactAnagramFinder().FindAnagrams(tc.words)
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestCompactAnagramFinder_FindAnagramGroups(t *testing.T) {
+	var words []string
+	for i := 0; i < 300; i++ {
+		words = append(words, fmt.Sprintf("w%03d", i), fmt.Sprintf("x%d", i), fmt.Sprintf("%03dw", i))
+	}
+
+	expected, err := NewSortMapAnagramFinder().FindAnagramGroups(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual, err := NewCompactAnagramFinder().FindAnagramGroups(words)
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestCompactStore_HashCollisions(t *testing.T) {
+	store := newCompactStore(func(signature []byte) uint64 { return 42 })
+
+	for _, word := range []string{"cat", "dog", "tac", "god", "bird", "act"} {
+		if err := store.add(word, []byte(Signature(word))); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	var actual [][]string
+	for _, group := range store.groupsOf() {
+		actual = append(actual, group.Words)
+	}
+
+	expected := [][]string{{"cat", "tac", "act"}, {"dog", "god"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+// BenchmarkGroupStore_Memory reports the heap bytes per word used to group freshly read
+// words by the sort & map approach and by the compact store.
+func BenchmarkGroupStore_Memory(b *testing.B) {
+	const n = 100000
+
+	// words are read as new strings, as the input sources do
+	stream := func(yield func(word, signature string) error) error {
+		rng := rand.New(rand.NewSource(1))
+		word := make([]byte, 10)
+		for i := 0; i < n; i++ {
+			for j := range word {
+				word[j] = byte('a' + rng.Intn(26))
+			}
+			if err := yield(string(word), ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	b.Run("sort_map", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bytesPerWord := heapGrowth(func() interface{} {
+				groups := make(map[string][]string)
+				stream(func(word, signature string) error {
+					signature = Signature(word)
+					groups[signature] = append(groups[signature], word)
+					return nil
+				})
+				return groups
+			}) / n
+			b.ReportMetric(bytesPerWord, "bytes/word")
+		}
+	})
+
+	b.Run("compact", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			bytesPerWord := heapGrowth(func() interface{} {
+				store, _ := NewCompactAnagramFinder().buildStore(stream)
+				return store
+			}) / n
+			b.ReportMetric(bytesPerWord, "bytes/word")
+		}
+	})
+}
+
+func heapGrowth(build func() interface{}) float64 {
+	var before, after runtime.MemStats
+
+	runtime.GC()
+	runtime.ReadMemStats(&before)
+
+	result := build()
+
+	runtime.GC()
+	runtime.ReadMemStats(&after)
+	runtime.KeepAlive(result)
+
+	return float64(after.HeapAlloc) - float64(before.HeapAlloc)
+}
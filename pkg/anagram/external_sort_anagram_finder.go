@@ -0,0 +1,278 @@
+package anagram
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+)
+
+const (
+	defaultMemoryBudget = 64 * 1024 * 1024 // 64MB
+	// maxMergeFanIn bounds the number of run files that are open and merged at once.
+	maxMergeFanIn = 64
+	// recordOverhead approximates the memory used by a buffered record besides its strings.
+	recordOverhead = 64
+	// cancelCheckInterval is the number of records processed between context checks.
+	cancelCheckInterval = 1024
+)
+
+// ExternalSortAnagramFinder implements the AnagramFinder interface for inputs that do not fit in memory.
+// Words are buffered with their signatures until the memory budget is reached, the buffer is then
+// sorted and spilled into a temporary run file. Once the stream is exhausted the runs are k-way merged
+// and consecutive words sharing a signature are emitted as a group.
+type ExternalSortAnagramFinder struct {
+	dir          string
+	memoryBudget int
+}
+
+func init() {
+	Register(Algorithm{
+		Name:         "external_sort",
+		Description:  "Sorts words by signature in run files on disk and merges them, for inputs larger than the memory.",
+		Capabilities: Capabilities{DiskBacked: true},
+		Options: []Option{
+			{Name: "tempDir", Type: "string", Env: "ANAGRAM_TEMP_DIR", Description: "Directory of the run files, defaults to the system temp directory."},
+			{Name: "memoryBudget", Type: "integer", Env: "ANAGRAM_MEMORY_BUDGET", Default: strconv.Itoa(defaultMemoryBudget), Description: "Bytes buffered in memory before a run is spilled."},
+		},
+		New: func(f *AnagramFinderFactory) AnagramFinder {
+			return NewExternalSortAnagramFinder(f.TempDir, f.MemoryBudget)
+		},
+	})
+}
+
+// NewExternalSortAnagramFinder creates a finder spilling its runs into dir, the system temp directory
+// is used when dir is empty. memoryBudget is the number of bytes buffered before a run is spilled.
+func NewExternalSortAnagramFinder(dir string, memoryBudget int) *ExternalSortAnagramFinder {
+	if memoryBudget <= 0 {
+		memoryBudget = defaultMemoryBudget
+	}
+	return &ExternalSortAnagramFinder{dir: dir, memoryBudget: memoryBudget}
+}
+
+// Finds anagrams among the words provided.
+// Time complexity: O(N*M*log(M) + N*log(N)) where N is the number of words and M is the maximum length of a word.
+// Space complexity: O(B) in memory where B is the memory budget and O(N*M) on disk, besides the output.
+func (e *ExternalSortAnagramFinder) FindAnagrams(words []string) ([][]string, error) {
+	return e.FindAnagramsStream(context.Background(), SliceWordStream(words))
+}
+
+// FindAnagramsStream collects the groups emitted by EmitAnagramGroups, which should be used instead
+// when the groups of a large input do not fit in memory.
+func (e *ExternalSortAnagramFinder) FindAnagramsStream(ctx context.Context, stream WordStream) ([][]string, error) {
+	result := make([][]string, 0)
+
+	err := e.EmitAnagramGroups(ctx, stream, func(group AnagramGroup) error {
+		result = append(result, group.Words)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (e *ExternalSortAnagramFinder) FindAnagramGroups(words []string) ([]AnagramGroup, error) {
+	return e.FindAnagramGroupsStream(context.Background(), SliceWordStream(words))
+}
+
+func (e *ExternalSortAnagramFinder) FindAnagramGroupsStream(ctx context.Context, stream WordStream) ([]AnagramGroup, error) {
+	result := make([]AnagramGroup, 0)
+
+	err := e.EmitAnagramGroups(ctx, stream, func(group AnagramGroup) error {
+		result = append(result, group)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// EmitAnagramGroups groups the words of the stream and passes each group to fn in signature order.
+// The temporary run files are removed before returning, including when ctx is canceled.
+func (e *ExternalSortAnagramFinder) EmitAnagramGroups(ctx context.Context, stream WordStream, fn func(group AnagramGroup) error) error {
+	dir, err := os.MkdirTemp(e.dir, "anagram-runs-")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(dir)
+
+	spiller := &runSpiller{dir: dir, memoryBudget: e.memoryBudget}
+	builder := NewSignatureBuilder(false)
+	position := 0
+
+	err = stream(func(word, signature string) error {
+		if position%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		if signature == "" {
+			signature = builder.Signature(word)
+		}
+
+		err := spiller.add(sortRecord{signature: signature, word: word, position: position})
+		position++
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(spiller.runs) == 0 {
+		spiller.sortBuffer()
+		return emitGroups(ctx, &sliceRecordIterator{records: spiller.buffer}, fn)
+	}
+
+	if err := spiller.spill(); err != nil {
+		return err
+	}
+
+	runs, err := spiller.mergePasses(ctx)
+	if err != nil {
+		return err
+	}
+
+	merger, err := newRunMerger(runs)
+	if err != nil {
+		return err
+	}
+	defer merger.Close()
+
+	return emitGroups(ctx, merger, fn)
+}
+
+// runSpiller buffers records and spills them into sorted run files.
+type runSpiller struct {
+	dir          string
+	memoryBudget int
+	buffer       []sortRecord
+	bufferSize   int
+	runs         []string
+	runCount     int
+}
+
+func (s *runSpiller) add(record sortRecord) error {
+	s.buffer = append(s.buffer, record)
+	s.bufferSize += len(record.signature) + len(record.word) + recordOverhead
+
+	if s.bufferSize >= s.memoryBudget {
+		return s.spill()
+	}
+
+	return nil
+}
+
+func (s *runSpiller) sortBuffer() {
+	sort.Slice(s.buffer, func(i, j int) bool { return lessRecord(s.buffer[i], s.buffer[j]) })
+}
+
+func (s *runSpiller) spill() error {
+	if len(s.buffer) == 0 {
+		return nil
+	}
+
+	s.sortBuffer()
+
+	path := s.nextRunPath()
+	if err := writeRun(path, &sliceRecordIterator{records: s.buffer}); err != nil {
+		return err
+	}
+
+	s.runs = append(s.runs, path)
+	s.buffer = s.buffer[:0]
+	s.bufferSize = 0
+
+	return nil
+}
+
+// mergePasses merges runs into larger runs until they can be merged at once.
+func (s *runSpiller) mergePasses(ctx context.Context) ([]string, error) {
+	runs := s.runs
+
+	for len(runs) > maxMergeFanIn {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		path := s.nextRunPath()
+		if err := mergeRuns(path, runs[:maxMergeFanIn]); err != nil {
+			return nil, err
+		}
+
+		runs = append(runs[maxMergeFanIn:], path)
+	}
+
+	return runs, nil
+}
+
+func (s *runSpiller) nextRunPath() string {
+	s.runCount++
+	return filepath.Join(s.dir, "run-"+strconv.Itoa(s.runCount)+".bin")
+}
+
+func mergeRuns(path string, runs []string) error {
+	merger, err := newRunMerger(runs)
+	if err != nil {
+		return err
+	}
+	defer merger.Close()
+
+	if err := writeRun(path, merger); err != nil {
+		return err
+	}
+
+	for _, run := range runs {
+		os.Remove(run)
+	}
+
+	return nil
+}
+
+// emitGroups passes every run of at least two consecutive records sharing a signature to fn.
+func emitGroups(ctx context.Context, records recordIterator, fn func(group AnagramGroup) error) error {
+	var group AnagramGroup
+
+	flush := func() error {
+		if len(group.Words) < 2 {
+			return nil
+		}
+		group.Histogram = letterHistogram(group.Signature)
+		return fn(group)
+	}
+
+	for i := 0; ; i++ {
+		if i%cancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		record, ok, err := records.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		if len(group.Words) > 0 && record.signature != group.Signature {
+			if err := flush(); err != nil {
+				return err
+			}
+			group = AnagramGroup{}
+		}
+
+		group.Signature = record.signature
+		group.Words = append(group.Words, record.word)
+		group.Positions = append(group.Positions, record.position)
+	}
+
+	return flush()
+}
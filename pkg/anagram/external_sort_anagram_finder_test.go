@@ -0,0 +1,109 @@
+package anagram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+)
+
+func TestExternalSortAnagramFinder_FindAnagramGroups(t *testing.T) {
+	var words []string
+	for i := 0; i < 500; i++ {
+		words = append(words, fmt.Sprintf("w%03d", i), fmt.Sprintf("%03dw", i), fmt.Sprintf("x%d", i))
+	}
+
+	testCases := []struct {
+		name         string
+		memoryBudget int
+	}{
+		{name: "in memory", memoryBudget: 0},
+		{name: "few runs", memoryBudget: 8 * 1024},
+		{name: "multiple merge passes", memoryBudget: 1},
+	}
+
+	expected, err := NewSortMapAnagramFinder().FindAnagramGroups(words)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			dir := t.TempDir()
+			esaf := NewExternalSortAnagramFinder(dir, tc.memoryBudget)
+
+			actual, err := esaf.FindAnagramGroups(words)
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Expected %v, got %v", expected, actual)
+			}
+
+			assertEmptyDir(t, dir)
+		})
+	}
+}
+
+func TestExternalSortAnagramFinder_FindAnagrams(t *testing.T) {
+	esaf := NewExternalSortAnagramFinder(t.TempDir(), 1)
+
+	actual, err := esaf.FindAnagrams([]string{"cat", "dog", "tac", "god", "good", "act"})
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := [][]string{{"cat", "tac", "act"}, {"dog", "god"}}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestExternalSortAnagramFinder_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	esaf := NewExternalSortAnagramFinder(dir, 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	stream := func(yield func(word, signature string) error) error {
+		for i := 0; i < 100; i++ {
+			if err := yield(fmt.Sprintf("word%d", i), ""); err != nil {
+				return err
+			}
+		}
+
+		cancel()
+
+		for i := 0; ; i++ {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			if err := yield(fmt.Sprintf("word%d", i), ""); err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := esaf.FindAnagramsStream(ctx, stream)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+
+	assertEmptyDir(t, dir)
+}
+
+func assertEmptyDir(t *testing.T, dir string) {
+	t.Helper()
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(entries) != 0 {
+		t.Errorf("expected temporary files to be removed, found %d entries", len(entries))
+	}
+}
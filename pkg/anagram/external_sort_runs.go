@@ -0,0 +1,243 @@
+package anagram
+
+import (
+	"bufio"
+	"container/heap"
+	"encoding/binary"
+	"errors"
+	"io"
+	"os"
+)
+
+const runBufferSize = 64 * 1024 // 64KB
+
+// sortRecord is a word along with its signature and position in the input.
+type sortRecord struct {
+	signature string
+	word      string
+	position  int
+}
+
+func lessRecord(a, b sortRecord) bool {
+	if a.signature != b.signature {
+		return a.signature < b.signature
+	}
+	return a.position < b.position
+}
+
+type recordIterator interface {
+	// next returns the next record, ok is false once the iterator is exhausted.
+	next() (record sortRecord, ok bool, err error)
+}
+
+type sliceRecordIterator struct {
+	records []sortRecord
+	index   int
+}
+
+func (it *sliceRecordIterator) next() (sortRecord, bool, error) {
+	if it.index >= len(it.records) {
+		return sortRecord{}, false, nil
+	}
+
+	record := it.records[it.index]
+	it.index++
+
+	return record, true, nil
+}
+
+// writeRun writes the records into a run file. Each record is encoded as the
+// uvarint length prefixed signature and word followed by the uvarint position.
+func writeRun(path string, records recordIterator) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	writer := bufio.NewWriterSize(file, runBufferSize)
+	scratch := make([]byte, binary.MaxVarintLen64)
+
+	writeUvarint := func(v uint64) error {
+		n := binary.PutUvarint(scratch, v)
+		_, err := writer.Write(scratch[:n])
+		return err
+	}
+
+	writeString := func(s string) error {
+		if err := writeUvarint(uint64(len(s))); err != nil {
+			return err
+		}
+		_, err := writer.WriteString(s)
+		return err
+	}
+
+	for {
+		record, ok, err := records.next()
+		if err != nil {
+			return err
+		}
+		if !ok {
+			break
+		}
+
+		if err := writeString(record.signature); err != nil {
+			return err
+		}
+		if err := writeString(record.word); err != nil {
+			return err
+		}
+		if err := writeUvarint(uint64(record.position)); err != nil {
+			return err
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+
+	return file.Close()
+}
+
+type runReader struct {
+	file    *os.File
+	reader  *bufio.Reader
+	current sortRecord
+}
+
+func openRun(path string) (*runReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &runReader{file: file, reader: bufio.NewReaderSize(file, runBufferSize)}, nil
+}
+
+// advance reads the next record into current, returns false at the end of the run.
+func (r *runReader) advance() (bool, error) {
+	signature, err := r.readString()
+	if errors.Is(err, io.EOF) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	word, err := r.readString()
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+
+	position, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		return false, unexpectedEOF(err)
+	}
+
+	r.current = sortRecord{signature: signature, word: word, position: int(position)}
+
+	return true, nil
+}
+
+func (r *runReader) readString() (string, error) {
+	length, err := binary.ReadUvarint(r.reader)
+	if err != nil {
+		return "", err
+	}
+
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(r.reader, buf); err != nil {
+		return "", unexpectedEOF(err)
+	}
+
+	return string(buf), nil
+}
+
+func unexpectedEOF(err error) error {
+	if errors.Is(err, io.EOF) {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+// runMerger k-way merges sorted run files.
+type runMerger struct {
+	readers []*runReader
+	heap    runHeap
+}
+
+func newRunMerger(runs []string) (*runMerger, error) {
+	m := &runMerger{}
+
+	for _, run := range runs {
+		reader, err := openRun(run)
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		m.readers = append(m.readers, reader)
+
+		ok, err := reader.advance()
+		if err != nil {
+			m.Close()
+			return nil, err
+		}
+		if ok {
+			m.heap = append(m.heap, reader)
+		}
+	}
+
+	heap.Init(&m.heap)
+
+	return m, nil
+}
+
+func (m *runMerger) next() (sortRecord, bool, error) {
+	if len(m.heap) == 0 {
+		return sortRecord{}, false, nil
+	}
+
+	top := m.heap[0]
+	record := top.current
+
+	ok, err := top.advance()
+	if err != nil {
+		return sortRecord{}, false, err
+	}
+
+	if ok {
+		heap.Fix(&m.heap, 0)
+	} else {
+		heap.Pop(&m.heap)
+	}
+
+	return record, true, nil
+}
+
+func (m *runMerger) Close() error {
+	var err error
+	for _, reader := range m.readers {
+		if closeErr := reader.file.Close(); closeErr != nil && err == nil {
+			err = closeErr
+		}
+	}
+	return err
+}
+
+type runHeap []*runReader
+
+func (h runHeap) Len() int           { return len(h) }
+func (h runHeap) Less(i, j int) bool { return lessRecord(h[i].current, h[j].current) }
+func (h runHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+
+func (h *runHeap) Push(x interface{}) {
+	*h = append(*h, x.(*runReader))
+}
+
+func (h *runHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	*h = old[:n-1]
+	return item
+}
@@ -1,22 +1,37 @@
 package anagram
 
-import "errors"
-
 type AnagramFinderFactoryInterface interface {
 	CreateAnagramFinder(algorithm string) (AnagramFinder, error)
 }
 
-type AnagramFinderFactory struct{}
+type AnagramFinderFactory struct {
+	// TempDir is where disk-backed algorithms spill their temporary files, defaults to the system temp directory.
+	TempDir string
+	// MemoryBudget is the number of bytes disk-backed algorithms buffer in memory, defaults to 64MB.
+	MemoryBudget int
+	// MemoryLimit is the number of bytes the automatic selection lets in-memory algorithms use, defaults to 256MB.
+	MemoryLimit int64
+}
+
+const defaultMemoryLimit = 256 * 1024 * 1024 // 256MB
 
 func NewAnagramFinderFactory() AnagramFinderFactoryInterface {
 	return &AnagramFinderFactory{}
 }
 
+// CreateAnagramFinder creates a finder of a registered algorithm.
 func (f *AnagramFinderFactory) CreateAnagramFinder(algorithm string) (AnagramFinder, error) {
-	switch algorithm {
-	case "sort_map":
-		return NewSortMapAnagramFinder(), nil
-	default:
-		return nil, errors.New("unknown algorithm")
+	a, ok := LookupAlgorithm(algorithm)
+	if !ok {
+		return nil, unknownAlgorithmError{name: algorithm}
+	}
+
+	return a.New(f), nil
+}
+
+func (f *AnagramFinderFactory) memoryLimit() int64 {
+	if f.MemoryLimit <= 0 {
+		return defaultMemoryLimit
 	}
+	return f.MemoryLimit
 }
@@ -0,0 +1,141 @@
+package anagram
+
+import (
+	"sort"
+	"sync"
+)
+
+// IncrementalAnagramFinder keeps a live set of words grouped by signature.
+// Words are added and removed one at a time and the groups are updated in place,
+// instead of grouping the whole set again after every change.
+// It is safe for concurrent use.
+type IncrementalAnagramFinder struct {
+	mu         sync.RWMutex
+	builder    *SignatureBuilder
+	key        []byte
+	groups     map[string]*incrementalGroup
+	signatures map[string]string
+}
+
+type incrementalGroup struct {
+	signature string
+	words     []string
+}
+
+func NewIncrementalAnagramFinder() *IncrementalAnagramFinder {
+	return &IncrementalAnagramFinder{
+		builder:    NewSignatureBuilder(false),
+		groups:     make(map[string]*incrementalGroup),
+		signatures: make(map[string]string),
+	}
+}
+
+// Add adds the word to the set. Returns false if the word was already present.
+// Words of the same group share a single signature string.
+// Time complexity: O(M*log(M)) where M is the length of the word.
+func (f *IncrementalAnagramFinder) Add(word string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.signatures[word]; ok {
+		return false
+	}
+
+	f.key = f.builder.AppendSignature(f.key[:0], word)
+
+	group, ok := f.groups[string(f.key)]
+	if !ok {
+		group = &incrementalGroup{signature: string(f.key)}
+		f.groups[group.signature] = group
+	}
+
+	f.signatures[word] = group.signature
+	group.words = append(group.words, word)
+
+	return true
+}
+
+// Remove removes the word from the set. Returns false if the word was not present.
+// Time complexity: O(G) where G is the size of the word's group.
+func (f *IncrementalAnagramFinder) Remove(word string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	signature, ok := f.signatures[word]
+	if !ok {
+		return false
+	}
+
+	delete(f.signatures, word)
+
+	group := f.groups[signature]
+	for i, member := range group.words {
+		if member == word {
+			group.words = append(group.words[:i], group.words[i+1:]...)
+			break
+		}
+	}
+
+	if len(group.words) == 0 {
+		delete(f.groups, signature)
+	}
+
+	return true
+}
+
+// Groups returns a snapshot of the anagram groups in the set, ordered by signature.
+// Words without anagrams in the set are not returned.
+func (f *IncrementalAnagramFinder) Groups() [][]string {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	signatures := make([]string, 0, len(f.groups))
+	for signature, group := range f.groups {
+		if len(group.words) > 1 {
+			signatures = append(signatures, signature)
+		}
+	}
+	sort.Strings(signatures)
+
+	result := make([][]string, len(signatures))
+	for i, signature := range signatures {
+		result[i] = copyGroup(f.groups[signature].words)
+	}
+
+	return result
+}
+
+// GroupOf returns a snapshot of the words in the set sharing the signature of word,
+// in insertion order. The word itself does not need to be in the set.
+func (f *IncrementalAnagramFinder) GroupOf(word string) []string {
+	signature := Signature(word)
+
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	group, ok := f.groups[signature]
+	if !ok {
+		return nil
+	}
+
+	return copyGroup(group.words)
+}
+
+// Len returns the number of words in the set.
+func (f *IncrementalAnagramFinder) Len() int {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return len(f.signatures)
+}
+
+func copyGroup(group []string) []string {
+	if len(group) == 0 {
+		return nil
+	}
+
+	result := make([]string, len(group))
+	copy(result, group)
+
+	return result
+}
@@ -0,0 +1,113 @@
+package anagram
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+)
+
+func TestIncrementalAnagramFinder(t *testing.T) {
+	f := NewIncrementalAnagramFinder()
+
+	for _, word := range []string{"cat", "dog", "tac", "god", "act", "hello"} {
+		if !f.Add(word) {
+			t.Errorf("expected %q to be added", word)
+		}
+	}
+
+	if f.Add("cat") {
+		t.Errorf("expected duplicate word to be rejected")
+	}
+
+	expected := [][]string{{"cat", "tac", "act"}, {"dog", "god"}}
+	if actual := f.Groups(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	if !f.Remove("tac") {
+		t.Errorf("expected tac to be removed")
+	}
+	if f.Remove("tac") {
+		t.Errorf("expected removing a missing word to fail")
+	}
+	if !f.Remove("god") {
+		t.Errorf("expected god to be removed")
+	}
+
+	expected = [][]string{{"cat", "act"}}
+	if actual := f.Groups(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+
+	testCases := []struct {
+		word     string
+		expected []string
+	}{
+		{word: "cat", expected: []string{"cat", "act"}},
+		{word: "Tca", expected: []string{"cat", "act"}},
+		{word: "dog", expected: []string{"dog"}},
+		{word: "world", expected: nil},
+	}
+
+	for _, tc := range testCases {
+		if actual := f.GroupOf(tc.word); !reflect.DeepEqual(actual, tc.expected) {
+			t.Errorf("GroupOf(%q): expected %v, got %v", tc.word, tc.expected, actual)
+		}
+	}
+
+	if f.Len() != 4 {
+		t.Errorf("expected 4 words, got %d", f.Len())
+	}
+}
+
+func TestIncrementalAnagramFinder_Concurrent(t *testing.T) {
+	f := NewIncrementalAnagramFinder()
+
+	const goroutines, words = 8, 100
+	// the words of each goroutine are distinct and share their signatures with those of the others,
+	// e.g. "123" and "213", besides temporary words removed again
+	word := func(i, j int) string { return fmt.Sprintf("%d%d", i, j) }
+
+	var wg sync.WaitGroup
+	for i := 0; i < goroutines; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < words; j++ {
+				if !f.Add(word(i, j)) {
+					t.Errorf("word %q was already present", word(i, j))
+				}
+				temporary := "x" + word(i, j)
+				f.Add(temporary)
+				f.GroupOf(word(i, j))
+				f.Groups()
+				if !f.Remove(temporary) {
+					t.Errorf("word %q was not present", temporary)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if got := f.Len(); got != goroutines*words {
+		t.Errorf("expected %d words, got %d", goroutines*words, got)
+	}
+	for i := 0; i < goroutines; i++ {
+		for j := 0; j < words; j++ {
+			w := word(i, j)
+			if !contains(f.GroupOf(w), w) {
+				t.Errorf("group of %q does not contain it: %v", w, f.GroupOf(w))
+			}
+		}
+	}
+}
+
+func contains(words []string, word string) bool {
+	for _, w := range words {
+		if w == word {
+			return true
+		}
+	}
+	return false
+}
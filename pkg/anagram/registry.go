@@ -0,0 +1,99 @@
+package anagram
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+)
+
+// Algorithm describes an anagram finder registered under a name.
+type Algorithm struct {
+	Name         string       `json:"name"`
+	Description  string       `json:"description"`
+	Capabilities Capabilities `json:"capabilities"`
+	// Options are the settings of the factory the algorithm uses.
+	Options []Option `json:"options,omitempty"`
+	// New creates a finder configured by the factory.
+	New func(f *AnagramFinderFactory) AnagramFinder `json:"-"`
+}
+
+type Capabilities struct {
+	// InMemory algorithms hold all words in memory, they are the candidates of the automatic selection.
+	InMemory bool `json:"inMemory"`
+	// DiskBacked algorithms spill to temporary files and can group inputs larger than the memory.
+	DiskBacked bool `json:"diskBacked"`
+	// Automatic algorithms delegate to another algorithm selected for the input.
+	Automatic bool `json:"automatic"`
+}
+
+// Option describes a setting of an algorithm and the environment variable it is read from.
+type Option struct {
+	Name        string `json:"name"`
+	Type        string `json:"type"`
+	Env         string `json:"env,omitempty"`
+	Default     string `json:"default,omitempty"`
+	Description string `json:"description"`
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]Algorithm)
+)
+
+// Register makes an algorithm available under its name. It panics if the name is registered twice.
+func Register(a Algorithm) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if a.New == nil {
+		panic("anagram: Register algorithm " + a.Name + " without constructor")
+	}
+	if _, ok := registry[a.Name]; ok {
+		panic("anagram: Register called twice for algorithm " + a.Name)
+	}
+
+	registry[a.Name] = a
+}
+
+// LookupAlgorithm returns the algorithm registered under the name.
+func LookupAlgorithm(name string) (Algorithm, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	a, ok := registry[name]
+	return a, ok
+}
+
+// Algorithms returns the registered algorithms sorted by name.
+func Algorithms() []Algorithm {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	algorithms := make([]Algorithm, 0, len(registry))
+	for _, a := range registry {
+		algorithms = append(algorithms, a)
+	}
+	sort.Slice(algorithms, func(i, j int) bool { return algorithms[i].Name < algorithms[j].Name })
+
+	return algorithms
+}
+
+// AlgorithmNames returns the names of the registered algorithms in sorted order.
+func AlgorithmNames() []string {
+	algorithms := Algorithms()
+
+	names := make([]string, len(algorithms))
+	for i, a := range algorithms {
+		names[i] = a.Name
+	}
+
+	return names
+}
+
+type unknownAlgorithmError struct {
+	name string
+}
+
+func (e unknownAlgorithmError) Error() string {
+	return fmt.Sprintf("unknown algorithm %q", e.name)
+}
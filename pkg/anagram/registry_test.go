@@ -0,0 +1,41 @@
+package anagram
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAlgorithmNames(t *testing.T) {
+	expected := []string{"auto", "compact", "external_sort", "sort_map"}
+
+	if actual := AlgorithmNames(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected Register to panic for a duplicate name")
+		}
+	}()
+
+	Register(Algorithm{
+		Name: "sort_map",
+		New:  func(*AnagramFinderFactory) AnagramFinder { return NewSortMapAnagramFinder() },
+	})
+}
+
+func TestAnagramFinderFactory_CreateAnagramFinder(t *testing.T) {
+	factory := &AnagramFinderFactory{}
+
+	for _, name := range AlgorithmNames() {
+		if finder, err := factory.CreateAnagramFinder(name); err != nil || finder == nil {
+			t.Errorf("CreateAnagramFinder(%q) returned %v, %v", name, finder, err)
+		}
+	}
+
+	if _, err := factory.CreateAnagramFinder("basic"); err == nil {
+		t.Errorf("Expected an error for an unknown algorithm")
+	}
+}
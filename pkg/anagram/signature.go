@@ -0,0 +1,206 @@
+package anagram
+
+import (
+	"sort"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// insertionSortThreshold is the length up to which letters are sorted by insertion sort,
+	// longer ASCII words are counting sorted.
+	insertionSortThreshold = 12
+	// maxInternedSignatures bounds the number of signatures kept by an interning builder.
+	maxInternedSignatures = 1 << 16
+)
+
+// SignatureBuilder computes the canonical signatures of words: spaces are removed, letters are
+// lowercased and sorted. The scratch buffers are reused between words, so AppendSignature does
+// not allocate once the buffers have grown to the longest word.
+// A SignatureBuilder is not safe for concurrent use, use one per goroutine.
+type SignatureBuilder struct {
+	buf      []byte
+	runes    runeSlice
+	interned map[string]string
+}
+
+// NewSignatureBuilder creates a builder. With intern set, Signature returns the same string for
+// repeated signatures instead of allocating a new one each time.
+func NewSignatureBuilder(intern bool) *SignatureBuilder {
+	b := &SignatureBuilder{}
+	if intern {
+		b.interned = make(map[string]string)
+	}
+	return b
+}
+
+var signatureBuilderPool = sync.Pool{
+	New: func() interface{} { return NewSignatureBuilder(false) },
+}
+
+// Signature returns the canonical signature of the word, anagrams share the same signature.
+// It is safe for concurrent use.
+func Signature(word string) string {
+	b := signatureBuilderPool.Get().(*SignatureBuilder)
+	defer signatureBuilderPool.Put(b)
+
+	return b.Signature(word)
+}
+
+// Signature returns the signature of the word as a string.
+func (b *SignatureBuilder) Signature(word string) string {
+	b.buf = b.AppendSignature(b.buf[:0], word)
+
+	if b.interned == nil {
+		return string(b.buf)
+	}
+
+	if signature, ok := b.interned[string(b.buf)]; ok {
+		return signature
+	}
+
+	signature := string(b.buf)
+	if len(b.interned) < maxInternedSignatures {
+		b.interned[signature] = signature
+	}
+
+	return signature
+}
+
+// AppendSignature appends the signature of the word to dst and returns the extended buffer.
+// The returned bytes can be used for map lookups through string(...) without allocating.
+func (b *SignatureBuilder) AppendSignature(dst []byte, word string) []byte {
+	return appendSignature(b, dst, word)
+}
+
+// appendSignature is shared by string and byte slice words, so that words stored in byte
+// buffers do not have to be converted to strings.
+func appendSignature[T string | []byte](b *SignatureBuilder, dst []byte, word T) []byte {
+	for i := 0; i < len(word); i++ {
+		if word[i] >= utf8.RuneSelf {
+			return appendUnicodeSignature(b, dst, word)
+		}
+	}
+
+	start := len(dst)
+	for i := 0; i < len(word); i++ {
+		c := word[i]
+		if c == ' ' {
+			continue
+		}
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		dst = append(dst, c)
+	}
+
+	letters := dst[start:]
+	if len(letters) <= insertionSortThreshold {
+		insertionSortBytes(letters)
+	} else {
+		countingSortBytes(letters)
+	}
+
+	return dst
+}
+
+// appendUnicodeSignature is the slow path for words containing non-ASCII letters.
+// Invalid UTF-8 bytes are replaced by utf8.RuneError as strings.ToLower does.
+func appendUnicodeSignature[T string | []byte](b *SignatureBuilder, dst []byte, word T) []byte {
+	runes := b.runes[:0]
+	for _, r := range string(word) {
+		if r == ' ' {
+			continue
+		}
+		runes = append(runes, unicode.ToLower(r))
+	}
+	b.runes = runes
+
+	if len(runes) <= insertionSortThreshold {
+		insertionSortRunes(runes)
+	} else {
+		// sorting through the builder's field avoids allocating an interface value
+		sort.Sort(&b.runes)
+	}
+
+	for _, r := range runes {
+		dst = utf8.AppendRune(dst, r)
+	}
+
+	return dst
+}
+
+func insertionSortBytes(s []byte) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j] < s[j-1]; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+// countingSortBytes sorts ASCII bytes in O(N) using one counter per character.
+func countingSortBytes(s []byte) {
+	var counts [utf8.RuneSelf]int
+	for _, c := range s {
+		counts[c]++
+	}
+
+	i := 0
+	for c, count := range counts {
+		for ; count > 0; count-- {
+			s[i] = byte(c)
+			i++
+		}
+	}
+}
+
+func insertionSortRunes(s []rune) {
+	for i := 1; i < len(s); i++ {
+		for j := i; j > 0 && s[j] < s[j-1]; j-- {
+			s[j], s[j-1] = s[j-1], s[j]
+		}
+	}
+}
+
+type runeSlice []rune
+
+func (s *runeSlice) Len() int           { return len(*s) }
+func (s *runeSlice) Less(i, j int) bool { return (*s)[i] < (*s)[j] }
+func (s *runeSlice) Swap(i, j int)      { (*s)[i], (*s)[j] = (*s)[j], (*s)[i] }
+
+// signatureIndex assigns dense indexes to signatures. Looking up a known signature does not
+// allocate, a signature string is only allocated for the first word of each group.
+type signatureIndex struct {
+	builder    *SignatureBuilder
+	key        []byte
+	indexes    map[string]int
+	signatures []string
+}
+
+func newSignatureIndex() *signatureIndex {
+	return &signatureIndex{
+		builder: NewSignatureBuilder(false),
+		indexes: make(map[string]int),
+	}
+}
+
+// indexOf returns the index of the word's signature, added reports whether it is a new signature.
+// A non-empty signature is used as is instead of being computed from the word.
+func (x *signatureIndex) indexOf(word, signature string) (index int, added bool) {
+	if signature == "" {
+		x.key = x.builder.AppendSignature(x.key[:0], word)
+		if index, ok := x.indexes[string(x.key)]; ok {
+			return index, false
+		}
+		signature = string(x.key)
+	} else if index, ok := x.indexes[signature]; ok {
+		return index, false
+	}
+
+	index = len(x.signatures)
+	x.indexes[signature] = index
+	x.signatures = append(x.signatures, signature)
+
+	return index, true
+}
@@ -0,0 +1,194 @@
+package anagram
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// referenceSignature is the allocating implementation the builder replaces.
+func referenceSignature(word string) string {
+	word = strings.ReplaceAll(word, " ", "")
+	word = strings.ToLower(word)
+
+	letters := strings.Split(word, "")
+	sort.Strings(letters)
+
+	return strings.Join(letters, "")
+}
+
+var signatureTestWords = []string{
+	"",
+	" ",
+	"cat",
+	"Nag A Ram",
+	"hello!",
+	"the quick brown fox jumps over the lazy dog",
+	"ÄRGER",
+	"régal",
+	"Ängstlichkeit und Übermut",
+	"日本語",
+	"\xff\xfeab",
+	"a\xc3",
+}
+
+func TestSignatureBuilder_Signature(t *testing.T) {
+	for _, intern := range []bool{false, true} {
+		builder := NewSignatureBuilder(intern)
+
+		for _, word := range signatureTestWords {
+			expected := referenceSignature(word)
+
+			if actual := builder.Signature(word); actual != expected {
+				t.Errorf("intern=%v: signature of %q: expected %q, got %q", intern, word, expected, actual)
+			}
+			if actual := Signature(word); actual != expected {
+				t.Errorf("pooled signature of %q: expected %q, got %q", word, expected, actual)
+			}
+		}
+	}
+}
+
+func TestSignatureBuilder_Allocations(t *testing.T) {
+	builder := NewSignatureBuilder(true)
+	buf := make([]byte, 0, 64)
+
+	testCases := []struct {
+		name string
+		fn   func()
+	}{
+		{
+			name: "append ascii signature",
+			fn:   func() { buf = builder.AppendSignature(buf[:0], "The Quick Brown Fox") },
+		},
+		{
+			name: "append short unicode signature",
+			fn:   func() { buf = builder.AppendSignature(buf[:0], "Ärger") },
+		},
+		{
+			name: "append long unicode signature",
+			fn:   func() { buf = builder.AppendSignature(buf[:0], "Ängstlichkeit und Übermut") },
+		},
+		{
+			name: "append byte slice signature",
+			fn:   func() { buf = appendSignature(builder, buf[:0], []byte("Ängstlichkeit und Übermut")) },
+		},
+		{
+			name: "interned signature",
+			fn:   func() { builder.Signature("Nag A Ram") },
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.fn()
+			if allocs := testing.AllocsPerRun(100, tc.fn); allocs != 0 {
+				t.Errorf("expected no allocations, got %v", allocs)
+			}
+		})
+	}
+}
+
+func TestSignatureIndex(t *testing.T) {
+	index := newSignatureIndex()
+
+	for _, word := range []string{"cat", "dog", "tac"} {
+		index.indexOf(word, "")
+	}
+
+	if i, added := index.indexOf("act", ""); i != 0 || added {
+		t.Errorf("expected existing index 0, got %d (added %v)", i, added)
+	}
+	if i, added := index.indexOf("god", "dgo"); i != 1 || added {
+		t.Errorf("expected pre-computed signature to match index 1, got %d (added %v)", i, added)
+	}
+
+	if allocs := testing.AllocsPerRun(100, func() { index.indexOf("tca", "") }); allocs != 0 {
+		t.Errorf("expected no allocations for known signatures, got %v", allocs)
+	}
+}
+
+func benchmarkWords() []string {
+	var words []string
+	for i := 0; i < 1000; i++ {
+		words = append(words, fmt.Sprintf("Anagram %d", i), "listen", "Silent", "the quick brown fox jumps")
+	}
+	return words
+}
+
+func BenchmarkReferenceSignature(b *testing.B) {
+	words := benchmarkWords()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		referenceSignature(words[i%len(words)])
+	}
+}
+
+func BenchmarkSignatureBuilder_AppendSignature(b *testing.B) {
+	words := benchmarkWords()
+	builder := NewSignatureBuilder(false)
+	var buf []byte
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		buf = builder.AppendSignature(buf[:0], words[i%len(words)])
+	}
+}
+
+func BenchmarkSignatureBuilder_AppendSignature_Unicode(b *testing.B) {
+	builder := NewSignatureBuilder(false)
+	var buf []byte
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		buf = builder.AppendSignature(buf[:0], "Ängstlichkeit")
+	}
+}
+
+func BenchmarkSignatureBuilder_Signature(b *testing.B) {
+	words := benchmarkWords()
+	builder := NewSignatureBuilder(false)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		builder.Signature(words[i%len(words)])
+	}
+}
+
+func BenchmarkSignatureBuilder_Signature_Interned(b *testing.B) {
+	words := benchmarkWords()
+	builder := NewSignatureBuilder(true)
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		builder.Signature(words[i%len(words)])
+	}
+}
+
+func BenchmarkSignature(b *testing.B) {
+	words := benchmarkWords()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		Signature(words[i%len(words)])
+	}
+}
+
+func BenchmarkSortMapAnagramFinder_FindAnagrams(b *testing.B) {
+	words := benchmarkWords()
+	finder := NewSortMapAnagramFinder()
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		finder.FindAnagrams(words)
+	}
+}
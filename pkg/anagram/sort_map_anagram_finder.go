@@ -1,13 +1,21 @@
 package anagram
 
 import (
-	"sort"
-	"strings"
+	"context"
 )
 
 // SortMapAnagramFinder implements the AnagramFinder interface using a basic sort & map approach.
 type SortMapAnagramFinder struct{}
 
+func init() {
+	Register(Algorithm{
+		Name:         "sort_map",
+		Description:  "Groups words in a map keyed by their sorted letters.",
+		Capabilities: Capabilities{InMemory: true},
+		New:          func(*AnagramFinderFactory) AnagramFinder { return NewSortMapAnagramFinder() },
+	})
+}
+
 func NewSortMapAnagramFinder() *SortMapAnagramFinder {
 	return &SortMapAnagramFinder{}
 }
@@ -17,12 +25,24 @@ func NewSortMapAnagramFinder() *SortMapAnagramFinder {
 // Time complexity: O(N*M*log(M)) where N is the number of words and M is the maximum length of a word.
 // Space complexity: O(N*M), the size of the output structure.
 func (b *SortMapAnagramFinder) FindAnagrams(words []string) ([][]string, error) {
-	anagramGroups := make(map[string][]string)
+	return b.FindAnagramsStream(context.Background(), SliceWordStream(words))
+}
 
-	for _, word := range words {
+// Finds anagrams among the words of the stream, grouping each word as it arrives.
+func (b *SortMapAnagramFinder) FindAnagramsStream(ctx context.Context, stream WordStream) ([][]string, error) {
+	index := newSignatureIndex()
+	var anagramGroups [][]string
 
-		sortedWord := sortWord(word)
-		anagramGroups[sortedWord] = append(anagramGroups[sortedWord], word)
+	err := stream(func(word, signature string) error {
+		i, added := index.indexOf(word, signature)
+		if added {
+			anagramGroups = append(anagramGroups, nil)
+		}
+		anagramGroups[i] = append(anagramGroups[i], word)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
 	result := make([][]string, 0, len(anagramGroups))
@@ -36,17 +56,46 @@ func (b *SortMapAnagramFinder) FindAnagrams(words []string) ([][]string, error)
 	return result, nil
 }
 
-// todo: handles both normalization and sorting, split into two separate functions
-// sortWord takes a word as input, removes spaces, converts it to lowercase, and returns the sorted string.
-// This function is used as a helper to normalize the words for anagram comparison.
-// Time complexity: O(M*log(M)).
-// Space complexity: O(M), where M is the length of the word.
-func sortWord(word string) string {
-	word = strings.ReplaceAll(word, " ", "")
-	word = strings.ToLower(word)
+// Finds anagrams among the words provided and describes each group with its
+// signature, letter histogram and the positions of its members in words.
+// Groups are ordered by signature.
+func (b *SortMapAnagramFinder) FindAnagramGroups(words []string) ([]AnagramGroup, error) {
+	return b.FindAnagramGroupsStream(context.Background(), SliceWordStream(words))
+}
+
+// Detailed counterpart of FindAnagramsStream, positions count the words of the stream.
+func (b *SortMapAnagramFinder) FindAnagramGroupsStream(ctx context.Context, stream WordStream) ([]AnagramGroup, error) {
+	index := newSignatureIndex()
+	var anagramGroups []AnagramGroup
+	position := 0
+
+	err := stream(func(word, signature string) error {
+		i, added := index.indexOf(word, signature)
+		if added {
+			anagramGroups = append(anagramGroups, AnagramGroup{Signature: index.signatures[i]})
+		}
+
+		group := &anagramGroups[i]
+		group.Words = append(group.Words, word)
+		group.Positions = append(group.Positions, position)
+		position++
 
-	letters := strings.Split(word, "")
-	sort.Strings(letters)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]AnagramGroup, 0, len(anagramGroups))
 
-	return strings.Join(letters, "")
+	for _, group := range anagramGroups {
+		if len(group.Words) > 1 {
+			group.Histogram = letterHistogram(group.Signature)
+			result = append(result, group)
+		}
+	}
+
+	sortAnagramGroups(result)
+
+	return result, nil
 }
@@ -72,3 +72,62 @@ func TestSortMapAnagramFinder_FindAnagrams(t *testing.T) {
 		})
 	}
 }
+
+func TestSortMapAnagramFinder_FindAnagramGroups(t *testing.T) {
+	testCases := []struct {
+		name     string
+		words    []string
+		expected []AnagramGroup
+	}{
+		{
+			name:     "no anagrams",
+			words:    []string{"hello", "world"},
+			expected: []AnagramGroup{},
+		},
+		{
+			name:  "groups ordered by signature",
+			words: []string{"dog", "cat", "god", "tac"},
+			expected: []AnagramGroup{
+				{
+					Signature: "act",
+					Histogram: map[string]int{"a": 1, "c": 1, "t": 1},
+					Words:     []string{"cat", "tac"},
+					Positions: []int{1, 3},
+				},
+				{
+					Signature: "dgo",
+					Histogram: map[string]int{"d": 1, "g": 1, "o": 1},
+					Words:     []string{"dog", "god"},
+					Positions: []int{0, 2},
+				},
+			},
+		},
+		{
+			name:  "repeated letters and duplicates",
+			words: []string{"Anagram", "nag a ram", "anagram"},
+			expected: []AnagramGroup{
+				{
+					Signature: "aaagmnr",
+					Histogram: map[string]int{"a": 3, "g": 1, "m": 1, "n": 1, "r": 1},
+					Words:     []string{"Anagram", "nag a ram", "anagram"},
+					Positions: []int{0, 1, 2},
+				},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			smaf := NewSortMapAnagramFinder()
+			actual, err := smaf.FindAnagramGroups(tc.words)
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("Expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
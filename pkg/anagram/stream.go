@@ -0,0 +1,76 @@
+package anagram
+
+import "context"
+
+// WordStream yields words one at a time to yield until the input is exhausted.
+// Streams may pass the pre-computed signature of the word, finders compute it when it is empty.
+// An error returned by yield stops the stream and is returned by the stream.
+type WordStream func(yield func(word, signature string) error) error
+
+// StreamingAnagramFinder is implemented by finders that consume their input incrementally.
+// ctx is observed by finders doing work after the stream has been consumed.
+type StreamingAnagramFinder interface {
+	FindAnagramsStream(ctx context.Context, stream WordStream) ([][]string, error)
+	FindAnagramGroupsStream(ctx context.Context, stream WordStream) ([]AnagramGroup, error)
+}
+
+// EmittingAnagramFinder is implemented by finders passing each group to fn as soon as it is found,
+// so that callers can write out the groups without holding all of them in memory.
+type EmittingAnagramFinder interface {
+	EmitAnagramGroups(ctx context.Context, stream WordStream, fn func(group AnagramGroup) error) error
+}
+
+// SliceWordStream streams the words of a slice.
+func SliceWordStream(words []string) WordStream {
+	return func(yield func(word, signature string) error) error {
+		for _, word := range words {
+			if err := yield(word, ""); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// StreamAnagrams finds the anagram groups in the stream using the given finder.
+// Finders that do not implement StreamingAnagramFinder receive the collected words.
+func StreamAnagrams(ctx context.Context, finder AnagramFinder, stream WordStream) ([][]string, error) {
+	if sf, ok := finder.(StreamingAnagramFinder); ok {
+		return sf.FindAnagramsStream(ctx, stream)
+	}
+
+	words, err := collectStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return finder.FindAnagrams(words)
+}
+
+// StreamAnagramGroups is the detailed counterpart of StreamAnagrams.
+func StreamAnagramGroups(ctx context.Context, finder AnagramFinder, stream WordStream) ([]AnagramGroup, error) {
+	if sf, ok := finder.(StreamingAnagramFinder); ok {
+		return sf.FindAnagramGroupsStream(ctx, stream)
+	}
+
+	words, err := collectStream(stream)
+	if err != nil {
+		return nil, err
+	}
+
+	return FindAnagramGroups(finder, words)
+}
+
+func collectStream(stream WordStream) ([]string, error) {
+	var words []string
+
+	err := stream(func(word, signature string) error {
+		words = append(words, word)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
@@ -0,0 +1,57 @@
+package anagram
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestStreamAnagrams(t *testing.T) {
+	words := []string{"cat", "dog", "tac"}
+
+	testCases := []struct {
+		name   string
+		finder AnagramFinder
+	}{
+		{
+			name:   "streaming finder",
+			finder: NewSortMapAnagramFinder(),
+		},
+		{
+			name:   "plain finder receives collected words",
+			finder: &plainAnagramFinder{groups: [][]string{{"cat", "tac"}}},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := StreamAnagrams(context.Background(), tc.finder, SliceWordStream(words))
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+
+			expected := [][]string{{"cat", "tac"}}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
+
+func TestStreamAnagramGroups_Error(t *testing.T) {
+	errSource := errors.New("source failed")
+	stream := func(yield func(word, signature string) error) error {
+		if err := yield("cat", ""); err != nil {
+			return err
+		}
+		return errSource
+	}
+
+	for _, finder := range []AnagramFinder{NewSortMapAnagramFinder(), &plainAnagramFinder{}} {
+		if _, err := StreamAnagramGroups(context.Background(), finder, stream); !errors.Is(err, errSource) {
+			t.Errorf("expected error %v, got %v", errSource, err)
+		}
+	}
+}
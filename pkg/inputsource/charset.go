@@ -0,0 +1,270 @@
+package inputsource
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+var (
+	ErrUnsupportedCharset = errors.New("unsupported charset")
+	ErrInvalidEncoding    = errors.New("invalid encoding")
+)
+
+const (
+	CharsetUTF8        = "utf-8"
+	CharsetUTF16       = "utf-16"
+	CharsetUTF16LE     = "utf-16le"
+	CharsetUTF16BE     = "utf-16be"
+	CharsetLatin1      = "iso-8859-1"
+	CharsetWindows1252 = "windows-1252"
+)
+
+// charsets maps the labels of the supported charsets to their canonical names.
+var charsets = map[string]string{
+	"":             CharsetUTF8,
+	"utf-8":        CharsetUTF8,
+	"utf8":         CharsetUTF8,
+	"us-ascii":     CharsetUTF8,
+	"ascii":        CharsetUTF8,
+	"utf-16":       CharsetUTF16,
+	"utf-16le":     CharsetUTF16LE,
+	"utf-16be":     CharsetUTF16BE,
+	"iso-8859-1":   CharsetLatin1,
+	"iso8859-1":    CharsetLatin1,
+	"latin1":       CharsetLatin1,
+	"l1":           CharsetLatin1,
+	"windows-1252": CharsetWindows1252,
+	"cp1252":       CharsetWindows1252,
+}
+
+// CharsetConfig is implemented by configs of input sources transcoding their input to UTF-8.
+type CharsetConfig interface {
+	SetCharset(charset string)
+}
+
+// CanonicalCharset returns the canonical name of a charset label, UTF-8 when it is empty.
+func CanonicalCharset(label string) (string, error) {
+	charset, ok := charsets[strings.ToLower(strings.TrimSpace(label))]
+	if !ok {
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedCharset, label)
+	}
+	return charset, nil
+}
+
+// MediaTypeCharset returns the charset parameter of a Content-Type header, empty if it has none.
+func MediaTypeCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	return params["charset"]
+}
+
+// EncodingError reports a byte sequence that is invalid in the charset of an input.
+type EncodingError struct {
+	Source  string
+	Charset string
+	// Line is the 1-based line of the invalid sequence.
+	Line int
+}
+
+func (e *EncodingError) Error() string {
+	return fmt.Sprintf("invalid %s sequence in %s at line %d", e.Charset, e.Source, e.Line)
+}
+
+func (e *EncodingError) Is(target error) bool {
+	return target == ErrInvalidEncoding
+}
+
+// validateLine reports a line of UTF-8 input that is not valid UTF-8.
+func validateLine(line []byte, source string, number int) error {
+	if !utf8.Valid(line) {
+		return &EncodingError{Source: source, Charset: CharsetUTF8, Line: number}
+	}
+	return nil
+}
+
+// bomCharset returns the charset of the byte order mark the input starts with and the size of the mark.
+func bomCharset(header []byte) (string, int) {
+	switch {
+	case bytes.HasPrefix(header, []byte{0xef, 0xbb, 0xbf}):
+		return CharsetUTF8, 3
+	case bytes.HasPrefix(header, []byte{0xff, 0xfe}):
+		return CharsetUTF16LE, 2
+	case bytes.HasPrefix(header, []byte{0xfe, 0xff}):
+		return CharsetUTF16BE, 2
+	default:
+		return "", 0
+	}
+}
+
+// inputCharset returns the charset of an input starting with header, the byte order mark takes
+// precedence over the given charset.
+func inputCharset(header []byte, charset string) (string, error) {
+	if bom, _ := bomCharset(header); bom != "" {
+		return bom, nil
+	}
+	return CanonicalCharset(charset)
+}
+
+// decodeCharset returns a reader of r transcoded from its charset to UTF-8. A byte order mark
+// overrides the charset and is removed. UTF-8 input is returned as is, it is validated line by line
+// when it is scanned.
+func decodeCharset(r io.Reader, charset, source string) io.Reader {
+	br := bufio.NewReader(r)
+	header, _ := br.Peek(3)
+
+	charset, err := inputCharset(header, charset)
+	if err != nil {
+		return &errReader{err: err}
+	}
+	if _, size := bomCharset(header); size > 0 {
+		br.Discard(size)
+	}
+
+	t := &transcoder{r: br, source: source, charset: charset, line: 1}
+	switch charset {
+	case CharsetUTF8:
+		return br
+	case CharsetUTF16, CharsetUTF16BE:
+		// UTF-16 without a byte order mark is big-endian
+		t.charset = CharsetUTF16BE
+		t.decode = decodeUTF16(false)
+	case CharsetUTF16LE:
+		t.decode = decodeUTF16(true)
+	case CharsetLatin1:
+		t.decode = decodeLatin1
+	case CharsetWindows1252:
+		t.decode = decodeWindows1252
+	}
+	return t
+}
+
+type errReader struct {
+	err error
+}
+
+func (e *errReader) Read([]byte) (int, error) {
+	return 0, e.err
+}
+
+// decodeFunc appends the UTF-8 encoding of the complete characters at the start of src to dst and
+// returns the number of bytes of src consumed. invalid is set when src starts with an invalid sequence.
+type decodeFunc func(dst, src []byte) (out []byte, consumed int, invalid bool)
+
+// transcoder reads its input in another charset as UTF-8.
+type transcoder struct {
+	r       io.Reader
+	decode  decodeFunc
+	source  string
+	charset string
+
+	buf []byte
+	in  []byte
+	out []byte
+	// line counts the lines of the output for the error of an invalid sequence.
+	line int
+	err  error
+}
+
+func (t *transcoder) Read(p []byte) (int, error) {
+	for len(t.out) == 0 {
+		if t.err != nil {
+			return 0, t.err
+		}
+
+		if t.buf == nil {
+			t.buf = make([]byte, 32*1024)
+		}
+		n, err := t.r.Read(t.buf)
+		t.in = append(t.in, t.buf[:n]...)
+
+		out, consumed, invalid := t.decode(t.out[:0], t.in)
+		t.out = out
+		t.in = t.in[consumed:]
+
+		switch {
+		case invalid, err == io.EOF && len(t.in) > 0:
+			// sequences left at the end of the input are incomplete
+			t.err = &EncodingError{Source: t.source, Charset: t.charset, Line: t.line + bytes.Count(t.out, []byte{'\n'})}
+		case err != nil:
+			t.err = err
+		}
+	}
+
+	n := copy(p, t.out)
+	t.line += bytes.Count(p[:n], []byte{'\n'})
+	t.out = t.out[n:]
+	return n, nil
+}
+
+func decodeUTF16(littleEndian bool) decodeFunc {
+	unit := func(b []byte) uint16 {
+		if littleEndian {
+			return uint16(b[0]) | uint16(b[1])<<8
+		}
+		return uint16(b[0])<<8 | uint16(b[1])
+	}
+
+	return func(dst, src []byte) ([]byte, int, bool) {
+		i := 0
+		for i+2 <= len(src) {
+			r := rune(unit(src[i:]))
+			size := 2
+
+			if utf16.IsSurrogate(r) {
+				if r >= 0xdc00 {
+					// a low surrogate without a high surrogate
+					return dst, i, true
+				}
+				if i+4 > len(src) {
+					break
+				}
+				r = utf16.DecodeRune(r, rune(unit(src[i+2:])))
+				if r == utf8.RuneError {
+					return dst, i, true
+				}
+				size = 4
+			}
+
+			dst = utf8.AppendRune(dst, r)
+			i += size
+		}
+		return dst, i, false
+	}
+}
+
+func decodeLatin1(dst, src []byte) ([]byte, int, bool) {
+	for _, b := range src {
+		dst = utf8.AppendRune(dst, rune(b))
+	}
+	return dst, len(src), false
+}
+
+// windows1252 holds the characters of the bytes 0x80 to 0x9f, which differ from Latin-1.
+// Unassigned bytes are 0.
+var windows1252 = [32]rune{
+	'€', 0, '‚', 'ƒ', '„', '…', '†', '‡', 'ˆ', '‰', 'Š', '‹', 'Œ', 0, 'Ž', 0,
+	0, '‘', '’', '“', '”', '•', '–', '—', '˜', '™', 'š', '›', 'œ', 0, 'ž', 'Ÿ',
+}
+
+func decodeWindows1252(dst, src []byte) ([]byte, int, bool) {
+	for i, b := range src {
+		r := rune(b)
+		if b >= 0x80 && b <= 0x9f {
+			r = windows1252[b-0x80]
+			if r == 0 {
+				return dst, i, true
+			}
+		}
+		dst = utf8.AppendRune(dst, r)
+	}
+	return dst, len(src), false
+}
@@ -0,0 +1,104 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestDecodeCharset(t *testing.T) {
+	tests := []struct {
+		name     string
+		charset  string
+		input    []byte
+		expected string
+		line     int
+	}{
+		{name: "UTF-8", input: []byte("café\n"), expected: "café\n"},
+		{name: "UTF-8 BOM", input: []byte("\xef\xbb\xbfcafé"), expected: "café"},
+		{name: "Latin-1", charset: "ISO-8859-1", input: []byte("caf\xe9\n\xa9"), expected: "café\n©"},
+		{name: "Windows-1252", charset: "cp1252", input: []byte("\x93caf\xe9\x94 \x80"), expected: "“café” €"},
+		{name: "Windows-1252 unassigned", charset: "windows-1252", input: []byte("cat\ntac\x81"), line: 2},
+		{name: "UTF-16LE BOM", input: []byte("\xff\xfec\x00a\x00t\x00\n\x00=\xd8\x00\xde"), expected: "cat\n😀"},
+		{name: "UTF-16BE BOM overrides charset", charset: "iso-8859-1", input: []byte("\xfe\xff\x00c\x00a\x00t"), expected: "cat"},
+		{name: "UTF-16 without BOM", charset: "utf-16", input: []byte("\x00c\x00a\x00t"), expected: "cat"},
+		{name: "UTF-16 lone surrogate", charset: "utf-16le", input: []byte("c\x00\n\x00\x00\xdc"), line: 2},
+		{name: "UTF-16 odd length", charset: "utf-16be", input: []byte("\x00c\x00"), line: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			// one byte at a time splits multi-byte sequences across reads
+			r := decodeCharset(iotest.OneByteReader(strings.NewReader(string(tc.input))), tc.charset, "words.txt")
+			actual, err := io.ReadAll(r)
+
+			if tc.line == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if string(actual) != tc.expected {
+					t.Errorf("expected %q, got %q", tc.expected, actual)
+				}
+				return
+			}
+
+			var encodingErr *EncodingError
+			if !errors.As(err, &encodingErr) || !errors.Is(err, ErrInvalidEncoding) {
+				t.Fatalf("expected an encoding error, got %v", err)
+			}
+			if encodingErr.Line != tc.line {
+				t.Errorf("expected line %d, got %d", tc.line, encodingErr.Line)
+			}
+		})
+	}
+}
+
+func TestCanonicalCharset(t *testing.T) {
+	if charset, err := CanonicalCharset(" Latin1 "); err != nil || charset != CharsetLatin1 {
+		t.Errorf("expected %s, got %s, %v", CharsetLatin1, charset, err)
+	}
+	if _, err := CanonicalCharset("koi8-r"); !errors.Is(err, ErrUnsupportedCharset) {
+		t.Errorf("expected error %v, got %v", ErrUnsupportedCharset, err)
+	}
+}
+
+func TestHttpFileInputSource_InvalidUTF8(t *testing.T) {
+	content := "listen\nsil\xe9nt\n"
+
+	source := NewHttpFileInputSource(NewMockMultipartFile(content), "words.txt")
+	_, err := source.GetWords()
+
+	expected := &EncodingError{Source: "words.txt", Charset: CharsetUTF8, Line: 2}
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+
+	// the parallel path reports the same line
+	err = ingestParallel(context.Background(), []byte(content), "words.txt", 4, 2, func(Word) error { return nil })
+	if !reflect.DeepEqual(err, expected) {
+		t.Errorf("expected error %v, got %v", expected, err)
+	}
+}
+
+func TestHttpFileInputSource_Charset(t *testing.T) {
+	config := &HttpFileConfig{}
+	config.SetFile(NewMockMultipartFile("caf\xe9\n\xe9fac\n"), "words.txt")
+	config.SetCharset("latin1")
+
+	source, err := (&InputSourceFactory{}).CreateInputSource("http_file", config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	words, err := source.GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := []string{"café", "éfac"}; !reflect.DeepEqual(Texts(words), expected) {
+		t.Errorf("expected words %v, got %v", expected, Texts(words))
+	}
+}
@@ -0,0 +1,230 @@
+package inputsource
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	ErrInvalidCsv = errors.New("malformed csv input")
+	ErrCsvColumn  = errors.New("csv column not found")
+)
+
+const csvSourceName = "csv"
+
+func init() {
+	Register(csvSourceName,
+		func() *CsvConfig { return &CsvConfig{} },
+		func(f *InputSourceFactory, c *CsvConfig) (InputSource, error) {
+			if c.File != nil {
+				source := NewHttpFileInputSource(c.File, c.Name)
+				source.compression = c.Compression
+				source.limits = f.decompressionLimits()
+				source.scan = c.Options.scan
+				source.charset = c.Charset
+				return source, nil
+			}
+
+			source := NewHttpUrlInputSource(c.URL, f.URLPolicy, f.URLCache)
+			if c.Cache == CacheBypass {
+				source.cache = nil
+			}
+			source.compression = c.Compression
+			source.limits = f.decompressionLimits()
+			source.scan = c.Options.scan
+			source.scanKey = c.Options.key()
+			source.charset = c.Charset
+			return source, nil
+		})
+}
+
+// CSVConfig is implemented by configs of input sources reading CSV.
+type CSVConfig interface {
+	SetCSVOptions(options CSVOptions)
+}
+
+// CSVOptions select the column of a CSV document holding the words.
+type CSVOptions struct {
+	// Delimiter is a single character separating the fields, a comma when empty. "tab" is accepted for TSV.
+	Delimiter string
+	// Column is the name of the column, which requires a header, or its 1-based index. The first
+	// column is read when empty.
+	Column string
+	// Header skips the first row, naming the columns.
+	Header bool
+}
+
+func (o CSVOptions) Validate() error {
+	if _, err := o.delimiter(); err != nil {
+		return err
+	}
+	if index, err := strconv.Atoi(o.Column); err == nil {
+		if index < 1 {
+			return fmt.Errorf("column index %d is not positive", index)
+		}
+	} else if o.Column != "" && !o.Header {
+		return errors.New("a column name requires a header")
+	}
+	return nil
+}
+
+func (o CSVOptions) delimiter() (rune, error) {
+	switch o.Delimiter {
+	case "":
+		return ',', nil
+	case "tab":
+		return '\t', nil
+	}
+
+	r, size := utf8.DecodeRuneInString(o.Delimiter)
+	if size != len(o.Delimiter) || r == utf8.RuneError || r == '"' || r == '\r' || r == '\n' {
+		return 0, fmt.Errorf("invalid delimiter %q", o.Delimiter)
+	}
+	return r, nil
+}
+
+// key identifies the options in the cache of URL documents.
+func (o CSVOptions) key() string {
+	return fmt.Sprintf("csv;%q;%q;%t", o.Delimiter, o.Column, o.Header)
+}
+
+// scan passes the non-empty cells of the selected column to fn, the row is reported as the line
+// and the 1-based index of the column as the column of a word. Rows are counted from 1 including the header.
+func (o CSVOptions) scan(ctx context.Context, r io.Reader, source string, fn WordFunc) error {
+	reader := csv.NewReader(r)
+	reader.Comma, _ = o.delimiter()
+	reader.FieldsPerRecord = -1
+	reader.ReuseRecord = true
+
+	column := 0
+	index, err := strconv.Atoi(o.Column)
+	byName := o.Column != "" && err != nil
+	if err == nil {
+		column = index - 1
+	}
+
+	row := 0
+	if o.Header {
+		header, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCsv, err)
+		}
+		row++
+
+		if byName {
+			column = headerIndex(header, o.Column)
+			if column < 0 {
+				return fmt.Errorf("%w: %q", ErrCsvColumn, o.Column)
+			}
+		}
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidCsv, err)
+		}
+		row++
+
+		if column >= len(record) {
+			return fmt.Errorf("%w: row %d has no column %d", ErrCsvColumn, row, column+1)
+		}
+
+		// rows are counted apart from lines, as quoted fields can span several lines
+		line, offset := reader.FieldPos(column)
+		if !utf8.ValidString(record[column]) {
+			return &EncodingError{Source: source, Charset: CharsetUTF8, Line: line}
+		}
+		text := strings.TrimSpace(record[column])
+		if text == "" {
+			continue
+		}
+		if err := fn(Word{Text: text, Source: source, Line: line, Column: offset}); err != nil {
+			return err
+		}
+	}
+}
+
+// headerIndex returns the index of the named column, -1 if the header does not name it.
+func headerIndex(header []string, name string) int {
+	// the byte order mark spreadsheet applications often start exports with has been removed by decodeCharset
+	for i, field := range header {
+		if strings.TrimSpace(field) == name {
+			return i
+		}
+	}
+	return -1
+}
+
+// CsvConfig holds a CSV document, either an uploaded file or the URL of a document, and the
+// options selecting the column of the words.
+type CsvConfig struct {
+	File multipart.File
+	Name string
+	URL  string
+	// Cache is either CacheDefault or CacheBypass, it only applies to URLs.
+	Cache string
+	// Compression is the format of the document, detected by its magic bytes when empty.
+	Compression string
+	// Charset is the charset of the document, UTF-8 or the charset of the URL content type when empty.
+	Charset string
+	Options CSVOptions
+}
+
+func (c *CsvConfig) SetCharset(charset string) {
+	c.Charset = charset
+}
+
+func (c *CsvConfig) SetFile(file multipart.File, name string) {
+	c.File = file
+	c.Name = name
+}
+
+func (c *CsvConfig) UnmarshalText(text []byte) error {
+	c.URL = string(text)
+	return nil
+}
+
+func (c *CsvConfig) SetCache(mode string) {
+	c.Cache = mode
+}
+
+func (c *CsvConfig) SetCompression(format string) {
+	c.Compression = format
+}
+
+func (c *CsvConfig) SetCSVOptions(options CSVOptions) {
+	c.Options = options
+}
+
+func (c *CsvConfig) Validate() error {
+	if c.File == nil {
+		url := HttpUrlConfig{URL: c.URL, Cache: c.Cache, Compression: c.Compression}
+		if err := url.Validate(); err != nil {
+			return fmt.Errorf("a file or %v", err)
+		}
+	} else if !compressions[c.Compression] {
+		return fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	if _, err := CanonicalCharset(c.Charset); err != nil {
+		return err
+	}
+	return c.Options.Validate()
+}
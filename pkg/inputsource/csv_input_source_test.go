@@ -0,0 +1,151 @@
+package inputsource
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestCsvInputSource_File(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		options  CSVOptions
+		expected []Word
+		err      error
+	}{
+		{
+			name:    "First column",
+			content: "cat,1\ntac,2\n",
+			expected: []Word{
+				{Text: "cat", Source: "words.csv", Line: 1, Column: 1},
+				{Text: "tac", Source: "words.csv", Line: 2, Column: 1},
+			},
+		},
+		{
+			name:    "Column by name with quoting",
+			content: "\ufeffid,word\n1,\"a,b\"\n2,\n3,\"b,a\"\n",
+			options: CSVOptions{Column: "word", Header: true},
+			expected: []Word{
+				{Text: "a,b", Source: "words.csv", Line: 2, Column: 3},
+				{Text: "b,a", Source: "words.csv", Line: 4, Column: 3},
+			},
+		},
+		{
+			name:    "Quoted line break",
+			content: "id,word\n1,\"new\nline\"\n2,cat\n3,tac\n",
+			options: CSVOptions{Column: "word", Header: true},
+			expected: []Word{
+				{Text: "new\nline", Source: "words.csv", Line: 2, Column: 3},
+				{Text: "cat", Source: "words.csv", Line: 4, Column: 3},
+				{Text: "tac", Source: "words.csv", Line: 5, Column: 3},
+			},
+		},
+		{
+			name:    "Tab delimiter and index",
+			content: "id\tword\n1\tcat\n2\ttac\n",
+			options: CSVOptions{Delimiter: "tab", Column: "2", Header: true},
+			expected: []Word{
+				{Text: "cat", Source: "words.csv", Line: 2, Column: 3},
+				{Text: "tac", Source: "words.csv", Line: 3, Column: 3},
+			},
+		},
+		{
+			name:    "Unknown column name",
+			content: "id,word\n1,cat\n",
+			options: CSVOptions{Column: "text", Header: true},
+			err:     ErrCsvColumn,
+		},
+		{
+			name:    "Short row",
+			content: "1;cat\n2\n",
+			options: CSVOptions{Delimiter: ";", Column: "2"},
+			err:     ErrCsvColumn,
+		},
+		{
+			name:    "Unterminated quote",
+			content: "\"cat\ntac\n",
+			err:     ErrInvalidCsv,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &CsvConfig{Options: tc.options}
+			config.SetFile(NewMockMultipartFile(tc.content), "words.csv")
+
+			source, err := (&InputSourceFactory{}).CreateInputSource("csv", config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			words, err := source.GetWords()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("expected words %v, got %v", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestCsvInputSource_Url(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "word,count\ncat,1\ntac,2\n")
+	}))
+	defer server.Close()
+
+	factory := &InputSourceFactory{URLPolicy: testUrlPolicy, URLCache: NewMemoryURLCache(1 << 20)}
+
+	// the cache must not serve the words of a different column
+	for _, column := range []string{"word", "count"} {
+		config := &CsvConfig{URL: server.URL, Options: CSVOptions{Column: column, Header: true}}
+		source, err := factory.CreateInputSource("csv", config)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		words, err := source.GetWords()
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		expected := []string{"cat", "tac"}
+		if column == "count" {
+			expected = []string{"1", "2"}
+		}
+		if !reflect.DeepEqual(Texts(words), expected) {
+			t.Errorf("column %s: expected words %v, got %v", column, expected, Texts(words))
+		}
+	}
+}
+
+func TestCsvConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		config  CsvConfig
+		wantErr bool
+	}{
+		{name: "Url", config: CsvConfig{URL: "http://example.com/words.csv"}},
+		{name: "Missing document", config: CsvConfig{}, wantErr: true},
+		{name: "Semicolon", config: CsvConfig{URL: "http://example.com", Options: CSVOptions{Delimiter: ";"}}},
+		{name: "Long delimiter", config: CsvConfig{URL: "http://example.com", Options: CSVOptions{Delimiter: ";;"}}, wantErr: true},
+		{name: "Quote delimiter", config: CsvConfig{URL: "http://example.com", Options: CSVOptions{Delimiter: "\""}}, wantErr: true},
+		{name: "Zero index", config: CsvConfig{URL: "http://example.com", Options: CSVOptions{Column: "0"}}, wantErr: true},
+		{name: "Name without header", config: CsvConfig{URL: "http://example.com", Options: CSVOptions{Column: "word"}}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := tc.config.Validate(); (err != nil) != tc.wantErr {
+				t.Errorf("expected error %v, got %v", tc.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,317 @@
+package inputsource
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"bytes"
+	"compress/bzip2"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+const (
+	// CompressionAuto detects the compression or archive format by the magic bytes of the input.
+	CompressionAuto  = ""
+	CompressionPlain = "plain"
+	CompressionGzip  = "gzip"
+	CompressionBzip2 = "bzip2"
+	CompressionZip   = "zip"
+	CompressionTar   = "tar"
+
+	defaultMaxDecompressedSize = 1024 * 1024 * 1024 // 1GB
+	defaultMaxCompressionRatio = 100
+	// ratioCheckThreshold is the decompressed size from which the compression ratio is checked,
+	// small inputs such as repeated words compress far better than real word lists.
+	ratioCheckThreshold = 1024 * 1024 // 1MB
+	// maxArchiveDepth bounds the nesting of formats, e.g. a tar archive compressed with gzip.
+	maxArchiveDepth = 2
+	sniffLen        = 512
+)
+
+var (
+	ErrDecompressionLimit = errors.New("decompressed input exceeds the size or compression ratio limit")
+	ErrInvalidArchive     = errors.New("compressed input is corrupt")
+)
+
+// archiveMediaTypes are the content types of compressed downloads accepted besides text.
+var archiveMediaTypes = map[string]bool{
+	"application/gzip":    true,
+	"application/x-gzip":  true,
+	"application/x-bzip2": true,
+	"application/zip":     true,
+	"application/x-tar":   true,
+	"application/x-gtar":  true,
+}
+
+var compressions = map[string]bool{
+	CompressionAuto:  true,
+	CompressionPlain: true,
+	CompressionGzip:  true,
+	CompressionBzip2: true,
+	CompressionZip:   true,
+	CompressionTar:   true,
+}
+
+// CompressionConfig is implemented by configs of input sources reading compressed and archived input.
+type CompressionConfig interface {
+	SetCompression(format string)
+}
+
+// DecompressionLimits guard against decompression bombs. MaxSize bounds the number of bytes
+// decompressed from an input and MaxRatio the ratio of decompressed to compressed bytes.
+type DecompressionLimits struct {
+	MaxSize  int64
+	MaxRatio int64
+	// TempDir is where zip archives read from a stream are spooled, the default temporary directory when empty.
+	// The factory sets it to its TempDir.
+	TempDir string
+}
+
+func (l DecompressionLimits) maxSize() int64 {
+	if l.MaxSize <= 0 {
+		return defaultMaxDecompressedSize
+	}
+	return l.MaxSize
+}
+
+func (l DecompressionLimits) maxRatio() int64 {
+	if l.MaxRatio <= 0 {
+		return defaultMaxCompressionRatio
+	}
+	return l.MaxRatio
+}
+
+// memberFunc is called with the name and content of every text member of an input.
+type memberFunc func(name string, r io.Reader) error
+
+// readMembers calls fn for every text member of the input, which is read once. Plain input is
+// a single member named name, members of archives are named name!/member. Zip archives need
+// random access, readerAt of the given size is used when it is not nil and the archive is
+// spooled to a temporary file in the TempDir of the limits otherwise.
+func readMembers(input io.Reader, readerAt io.ReaderAt, size int64, name, format string, limits DecompressionLimits, fn memberFunc) error {
+	counter := &countingReader{r: input}
+
+	d := &decompressor{limits: limits, compressed: func() int64 {
+		if counter.n > size {
+			return counter.n
+		}
+		return size
+	}}
+
+	r := bufio.NewReaderSize(counter, sniffLen)
+	if format == CompressionAuto {
+		format = detectCompression(peek(r))
+	}
+
+	return d.read(r, readerAt, size, name, format, 0, fn)
+}
+
+// detectCompression returns the format of the input starting with header.
+func detectCompression(header []byte) string {
+	switch {
+	case bytes.HasPrefix(header, []byte{0x1f, 0x8b}):
+		return CompressionGzip
+	case bytes.HasPrefix(header, []byte("BZh")):
+		return CompressionBzip2
+	case bytes.HasPrefix(header, []byte("PK\x03\x04")), bytes.HasPrefix(header, []byte("PK\x05\x06")):
+		return CompressionZip
+	case len(header) >= 262 && string(header[257:262]) == "ustar":
+		return CompressionTar
+	default:
+		return CompressionPlain
+	}
+}
+
+// fileCompression returns the compression of a file, detected by its magic bytes when it is not given.
+func fileCompression(r io.ReaderAt, compression string) string {
+	if compression != CompressionAuto {
+		return compression
+	}
+	header := make([]byte, sniffLen)
+	n, _ := r.ReadAt(header, 0)
+	return detectCompression(header[:n])
+}
+
+func peek(r *bufio.Reader) []byte {
+	header, _ := r.Peek(sniffLen)
+	return header
+}
+
+type decompressor struct {
+	limits       DecompressionLimits
+	compressed   func() int64
+	decompressed int64
+}
+
+func (d *decompressor) read(r *bufio.Reader, readerAt io.ReaderAt, size int64, name, format string, depth int, fn memberFunc) error {
+	switch format {
+	case CompressionPlain:
+		// the top-level input is read as is, like uncompressed uploads always were
+		if depth == 0 {
+			return fn(name, r)
+		}
+		return d.member(name, r, fn)
+
+	case CompressionGzip:
+		zr, err := gzip.NewReader(r)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+		defer zr.Close()
+		return d.nested(d.limit(zr), strings.TrimSuffix(name, ".gz"), depth, fn)
+
+	case CompressionBzip2:
+		return d.nested(d.limit(bzip2.NewReader(r)), strings.TrimSuffix(name, ".bz2"), depth, fn)
+
+	case CompressionTar:
+		tr := tar.NewReader(r)
+		for {
+			header, err := tr.Next()
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+			}
+			if header.Typeflag != tar.TypeReg {
+				continue
+			}
+			// tar does not compress, its content is limited by the input or the decompressor it is read from
+			if err := d.member(name+"!/"+header.Name, tr, fn); err != nil {
+				return err
+			}
+		}
+
+	case CompressionZip:
+		if readerAt == nil {
+			spool, n, err := d.spool(r)
+			if err != nil {
+				return err
+			}
+			defer func() {
+				spool.Close()
+				os.Remove(spool.Name())
+			}()
+			readerAt, size = spool, n
+		}
+
+		zr, err := zip.NewReader(readerAt, size)
+		if err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+		}
+
+		for _, file := range zr.File {
+			if file.FileInfo().IsDir() {
+				continue
+			}
+			if err := d.zipMember(name+"!/"+file.Name, file, fn); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	default:
+		return fmt.Errorf("%w: unknown format %s", ErrInvalidArchive, format)
+	}
+}
+
+// spool copies a zip archive read from a stream to a temporary file, as its directory is at its end.
+// Archives larger than the maximum decompressed size are rejected with ErrDecompressionLimit.
+func (d *decompressor) spool(r io.Reader) (*os.File, int64, error) {
+	f, err := os.CreateTemp(d.limits.TempDir, "anagram-zip-")
+	if err != nil {
+		return nil, 0, err
+	}
+
+	maxSize := d.limits.maxSize()
+	n, err := io.Copy(f, io.LimitReader(r, maxSize+1))
+	if err == nil && n > maxSize {
+		err = ErrDecompressionLimit
+	}
+	if err != nil {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, 0, err
+	}
+
+	return f, n, nil
+}
+
+func (d *decompressor) zipMember(name string, file *zip.File, fn memberFunc) error {
+	rc, err := file.Open()
+	if err != nil {
+		return fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+	defer rc.Close()
+
+	return d.member(name, d.limit(rc), fn)
+}
+
+// nested reads decompressed content, which may be an archive itself.
+func (d *decompressor) nested(r io.Reader, name string, depth int, fn memberFunc) error {
+	br := bufio.NewReaderSize(r, sniffLen)
+
+	format := CompressionPlain
+	if depth+1 < maxArchiveDepth {
+		format = detectCompression(peek(br))
+	}
+
+	return d.read(br, nil, -1, name, format, depth+1, fn)
+}
+
+// member passes a member to fn unless its content is not text.
+func (d *decompressor) member(name string, r io.Reader, fn memberFunc) error {
+	br := bufio.NewReaderSize(r, sniffLen)
+
+	header := peek(br)
+	if len(header) > 0 && !strings.HasPrefix(http.DetectContentType(header), "text/") {
+		return nil
+	}
+
+	return fn(name, br)
+}
+
+// limit counts the bytes decompressed from r and fails once the limits are exceeded.
+// Other read errors of the decompressor are reported as ErrInvalidArchive.
+func (d *decompressor) limit(r io.Reader) io.Reader {
+	return &decompressionLimitReader{r: r, d: d}
+}
+
+type decompressionLimitReader struct {
+	r io.Reader
+	d *decompressor
+}
+
+func (l *decompressionLimitReader) Read(p []byte) (int, error) {
+	n, err := l.r.Read(p)
+
+	d := l.d
+	d.decompressed += int64(n)
+	if d.decompressed > d.limits.maxSize() ||
+		(d.decompressed > ratioCheckThreshold && d.decompressed > d.compressed()*d.limits.maxRatio()) {
+		return 0, ErrDecompressionLimit
+	}
+
+	if err != nil && err != io.EOF && !errors.Is(err, ErrDecompressionLimit) && !errors.Is(err, ErrInvalidArchive) {
+		err = fmt.Errorf("%w: %w", ErrInvalidArchive, err)
+	}
+
+	return n, err
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,298 @@
+package inputsource
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// bzip2Words is "listen\nsilent\n" compressed with bzip2, which the standard library cannot write.
+var bzip2Words = []byte{
+	0x42, 0x5a, 0x68, 0x39, 0x31, 0x41, 0x59, 0x26, 0x53, 0x59, 0x12, 0x07, 0xb7, 0x10, 0x00, 0x00,
+	0x03, 0xc1, 0x80, 0x00, 0x10, 0x02, 0x25, 0x0c, 0x00, 0x20, 0x00, 0x31, 0x0c, 0x01, 0x0d, 0x0f,
+	0x28, 0x90, 0x62, 0x35, 0x31, 0x3c, 0x5d, 0xc9, 0x14, 0xe1, 0x42, 0x40, 0x48, 0x1e, 0xdc, 0x40,
+}
+
+func gzipData(t *testing.T, data []byte) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(data)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func zipData(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, name := range []string{"a.txt", "dir/", "image.png", "b.txt"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		w, _ := zw.Create(name)
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func tarData(t *testing.T, files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{"a.txt", "b.txt"} {
+		tw.WriteHeader(&tar.Header{Name: name, Mode: 0o600, Size: int64(len(files[name])), Typeflag: tar.TypeReg})
+		tw.Write([]byte(files[name]))
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReadMembers(t *testing.T) {
+	members := map[string]string{
+		"a.txt":     "listen\nsilent\n",
+		"dir/":      "",
+		"image.png": "\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR",
+		"b.txt":     "enlist\n",
+	}
+
+	tests := []struct {
+		name        string
+		data        []byte
+		compression string
+		expected    []string
+	}{
+		{
+			name:     "plain",
+			data:     []byte("listen\nsilent\n"),
+			expected: []string{"words: listen silent"},
+		},
+		{
+			name:     "gzip",
+			data:     gzipData(t, []byte("listen\nsilent\n")),
+			expected: []string{"words: listen silent"},
+		},
+		{
+			name:     "bzip2",
+			data:     bzip2Words,
+			expected: []string{"words: listen silent"},
+		},
+		{
+			name:     "zip with directories and binary members",
+			data:     zipData(t, members),
+			expected: []string{"words!/a.txt: listen silent", "words!/b.txt: enlist"},
+		},
+		{
+			name:     "tar",
+			data:     tarData(t, members),
+			expected: []string{"words!/a.txt: listen silent", "words!/b.txt: enlist"},
+		},
+		{
+			name:     "tar compressed with gzip",
+			data:     gzipData(t, tarData(t, members)),
+			expected: []string{"words!/a.txt: listen silent", "words!/b.txt: enlist"},
+		},
+		{
+			name:        "compression given by the client",
+			data:        []byte("listen\nsilent\n"),
+			compression: CompressionPlain,
+			expected:    []string{"words: listen silent"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var actual []string
+
+			err := readMembers(bytes.NewReader(tc.data), bytes.NewReader(tc.data), int64(len(tc.data)), "words", tc.compression, DecompressionLimits{},
+				func(name string, r io.Reader) error {
+					content, err := io.ReadAll(r)
+					actual = append(actual, name+": "+strings.Join(strings.Fields(string(content)), " "))
+					return err
+				})
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected members %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestReadMembers_Errors(t *testing.T) {
+	bomb := gzipData(t, bytes.Repeat([]byte("a\n"), 2*1024*1024))
+
+	tests := []struct {
+		name        string
+		data        []byte
+		limits      DecompressionLimits
+		expectedErr error
+	}{
+		{
+			name:        "compression ratio",
+			data:        bomb,
+			expectedErr: ErrDecompressionLimit,
+		},
+		{
+			name:        "decompressed size",
+			data:        gzipData(t, []byte(strings.Repeat("listen\n", 1000))),
+			limits:      DecompressionLimits{MaxSize: 1000},
+			expectedErr: ErrDecompressionLimit,
+		},
+		{
+			name:        "ratio within a raised limit",
+			data:        bomb,
+			limits:      DecompressionLimits{MaxRatio: 10000},
+			expectedErr: nil,
+		},
+		{
+			name:        "corrupt gzip",
+			data:        gzipData(t, []byte("listen\nsilent\n"))[:20],
+			expectedErr: ErrInvalidArchive,
+		},
+		{
+			name:        "streamed zip larger than the size limit",
+			data:        zipData(t, map[string]string{"a.txt": "listen\n"}),
+			limits:      DecompressionLimits{MaxSize: 50},
+			expectedErr: ErrDecompressionLimit,
+		},
+		{
+			name:        "corrupt zip",
+			data:        zipData(t, map[string]string{"a.txt": "listen\n"})[:40],
+			expectedErr: ErrInvalidArchive,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			err := readMembers(bytes.NewReader(tc.data), nil, -1, "words", CompressionAuto, tc.limits,
+				func(name string, r io.Reader) error {
+					_, err := io.Copy(io.Discard, r)
+					return err
+				})
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestReadMembers_ZipSpooledToTempDir(t *testing.T) {
+	dir := t.TempDir()
+	data := zipData(t, map[string]string{"a.txt": "listen\n"})
+
+	spooled := 0
+	err := readMembers(bytes.NewReader(data), nil, -1, "words", CompressionAuto, DecompressionLimits{TempDir: dir},
+		func(name string, r io.Reader) error {
+			entries, _ := os.ReadDir(dir)
+			spooled = len(entries)
+			return nil
+		})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if spooled != 1 {
+		t.Errorf("expected the archive to be spooled to the temp dir, found %d files", spooled)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the spooled archive to be removed")
+	}
+}
+
+func TestInputSourceFactory_DecompressionLimits(t *testing.T) {
+	f := &InputSourceFactory{TempDir: "/spool", DecompressionLimits: DecompressionLimits{MaxSize: 10}}
+	if limits := f.decompressionLimits(); limits.TempDir != "/spool" || limits.MaxSize != 10 {
+		t.Errorf("expected the limits to spool to the temp dir of the factory, got %+v", limits)
+	}
+}
+
+func TestHttpFileInputSource_GetWords_Compressed(t *testing.T) {
+	data := zipData(t, map[string]string{"a.txt": "listen\nsilent\n", "b.txt": "enlist\n"})
+
+	words, err := NewHttpFileInputSource(NewMockMultipartFile(string(data)), "words.zip").GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Word{
+		{Text: "listen", Source: "words.zip!/a.txt", Line: 1, Column: 1},
+		{Text: "silent", Source: "words.zip!/a.txt", Line: 2, Column: 1},
+		{Text: "enlist", Source: "words.zip!/b.txt", Line: 1, Column: 1},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestHttpUrlInputSource_GetWords_Compressed(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		compression string
+		expectedErr error
+	}{
+		{
+			name:        "gzip content type",
+			contentType: "application/gzip",
+		},
+		{
+			name:        "binary content type with compression given by the client",
+			contentType: "application/octet-stream",
+			compression: CompressionGzip,
+		},
+		{
+			name:        "binary content type",
+			contentType: "application/octet-stream",
+			expectedErr: ErrUrlContentType,
+		},
+	}
+
+	data := gzipData(t, []byte("listen\nsilent\n"))
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tc.contentType)
+				w.Write(data)
+			}))
+			defer server.Close()
+
+			source := NewHttpUrlInputSource(server.URL+"/words.gz", testUrlPolicy, nil)
+			source.compression = tc.compression
+
+			var texts []string
+			err := source.StreamWords(context.Background(), func(word Word) error {
+				texts = append(texts, fmt.Sprintf("%s@%s", word.Text, word.Source))
+				return nil
+			})
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			expected := []string{"listen@" + server.URL + "/words", "silent@" + server.URL + "/words"}
+			if err == nil && !reflect.DeepEqual(texts, expected) {
+				t.Errorf("expected words %v, got %v", expected, texts)
+			}
+		})
+	}
+}
@@ -0,0 +1,175 @@
+package inputsource
+
+import (
+	"bufio"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var ErrUnknownDictionary = errors.New("unknown dictionary")
+
+//go:embed dictionaries/*.txt
+var embeddedDictionaries embed.FS
+
+// Dictionary is a word list bundled with the server, read by the dictionary input source.
+type Dictionary struct {
+	Name        string `json:"name"`
+	Language    string `json:"language"`
+	Description string `json:"description,omitempty"`
+	License     string `json:"license,omitempty"`
+	// Words is the number of words of the list.
+	Words int `json:"words"`
+	// Embedded lists are built into the binary, the others are registered from a directory.
+	Embedded bool `json:"embedded"`
+
+	fsys fs.FS
+	file string
+}
+
+var (
+	dictionariesMu sync.RWMutex
+	dictionaries   = make(map[string]Dictionary)
+)
+
+func init() {
+	fsys, err := fs.Sub(embeddedDictionaries, "dictionaries")
+	if err == nil {
+		err = registerDictionaries(fsys, true)
+	}
+	if err != nil {
+		panic("inputsource: invalid embedded dictionaries: " + err.Error())
+	}
+}
+
+// RegisterDictionaries registers every .txt file at the root of fsys as a dictionary named after the
+// file. A dictionary lists one word per line, blank lines are skipped. It may start with a header of
+// lines such as "# language: en", giving its language, description and license. The language defaults
+// to the part of the name before the first hyphen. Names already registered are rejected.
+func RegisterDictionaries(fsys fs.FS) error {
+	return registerDictionaries(fsys, false)
+}
+
+func registerDictionaries(fsys fs.FS, embedded bool) error {
+	files, err := fs.Glob(fsys, "*.txt")
+	if err != nil {
+		return err
+	}
+
+	loaded := make([]Dictionary, 0, len(files))
+	for _, file := range files {
+		d, err := loadDictionary(fsys, file)
+		if err != nil {
+			return err
+		}
+		d.Embedded = embedded
+		loaded = append(loaded, d)
+	}
+
+	dictionariesMu.Lock()
+	defer dictionariesMu.Unlock()
+
+	for _, d := range loaded {
+		if _, ok := dictionaries[d.Name]; ok {
+			return fmt.Errorf("dictionary %s is already registered", d.Name)
+		}
+	}
+	for _, d := range loaded {
+		dictionaries[d.Name] = d
+	}
+
+	return nil
+}
+
+// loadDictionary reads the header of a dictionary file and counts its words.
+func loadDictionary(fsys fs.FS, file string) (Dictionary, error) {
+	name := strings.TrimSuffix(path.Base(file), ".txt")
+	if !validDictionaryName(name) {
+		return Dictionary{}, fmt.Errorf("invalid dictionary name %q, names consist of lowercase letters, digits and hyphens", name)
+	}
+
+	d := Dictionary{Name: name, Language: strings.SplitN(name, "-", 2)[0], fsys: fsys, file: file}
+
+	f, err := fsys.Open(file)
+	if err != nil {
+		return Dictionary{}, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxBufSize)
+
+	header := true
+	for line := 1; scanner.Scan(); line++ {
+		text := scanner.Text()
+		if header && strings.HasPrefix(text, "#") {
+			key, value, _ := strings.Cut(strings.TrimPrefix(text, "#"), ":")
+			switch strings.TrimSpace(key) {
+			case "language":
+				d.Language = strings.TrimSpace(value)
+			case "description":
+				d.Description = strings.TrimSpace(value)
+			case "license":
+				d.License = strings.TrimSpace(value)
+			}
+			continue
+		}
+		header = false
+
+		if err := validateLine(scanner.Bytes(), file, line); err != nil {
+			return Dictionary{}, err
+		}
+		if strings.TrimSpace(text) != "" {
+			d.Words++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return Dictionary{}, fmt.Errorf("reading dictionary %s: %w", name, err)
+	}
+
+	return d, nil
+}
+
+func validDictionaryName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func lookupDictionary(name string) (Dictionary, error) {
+	dictionariesMu.RLock()
+	defer dictionariesMu.RUnlock()
+
+	d, ok := dictionaries[name]
+	if !ok {
+		return Dictionary{}, fmt.Errorf("%w: %q", ErrUnknownDictionary, name)
+	}
+
+	return d, nil
+}
+
+// Dictionaries returns the registered dictionaries sorted by name.
+func Dictionaries() []Dictionary {
+	dictionariesMu.RLock()
+	defer dictionariesMu.RUnlock()
+
+	list := make([]Dictionary, 0, len(dictionaries))
+	for _, d := range dictionaries {
+		list = append(list, d)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
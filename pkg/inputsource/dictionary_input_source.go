@@ -0,0 +1,81 @@
+package inputsource
+
+import (
+	"context"
+	"io/fs"
+	"strings"
+)
+
+const dictionarySourceName = "dictionary"
+
+func init() {
+	Register(dictionarySourceName,
+		func() *DictionaryConfig { return &DictionaryConfig{} },
+		func(f *InputSourceFactory, c *DictionaryConfig) (InputSource, error) {
+			d, err := lookupDictionary(c.Name)
+			if err != nil {
+				return nil, err
+			}
+			return NewDictionaryInputSource(d), nil
+		})
+}
+
+// DictionaryConfig holds the name of a registered dictionary.
+type DictionaryConfig struct {
+	Name string
+}
+
+func (c *DictionaryConfig) UnmarshalText(text []byte) error {
+	c.Name = string(text)
+	return nil
+}
+
+func (c *DictionaryConfig) Validate() error {
+	_, err := lookupDictionary(c.Name)
+	return err
+}
+
+// DictionaryInputSource reads the words of a dictionary bundled with the server. The words report
+// dictionary:name as their source.
+type DictionaryInputSource struct {
+	dictionary Dictionary
+}
+
+func NewDictionaryInputSource(d Dictionary) *DictionaryInputSource {
+	return &DictionaryInputSource{dictionary: d}
+}
+
+// Size returns the size of the dictionary file, -1 if it cannot be determined.
+func (di *DictionaryInputSource) Size() int64 {
+	info, err := fs.Stat(di.dictionary.fsys, di.dictionary.file)
+	if err != nil {
+		return -1
+	}
+	return info.Size()
+}
+
+func (di *DictionaryInputSource) GetWords() ([]Word, error) {
+	return collectWords(di)
+}
+
+func (di *DictionaryInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	f, err := di.dictionary.fsys.Open(di.dictionary.file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	// the header and blank lines are skipped, the words keep their line numbers
+	header := true
+	return scanFileLines(ctx, f, dictionarySourceName+":"+di.dictionary.Name, func(word Word) error {
+		if header && strings.HasPrefix(word.Text, "#") {
+			return nil
+		}
+		header = false
+
+		if strings.TrimSpace(word.Text) == "" {
+			return nil
+		}
+		return fn(word)
+	})
+}
@@ -0,0 +1,112 @@
+package inputsource
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"testing/fstest"
+)
+
+func TestDictionaries_Embedded(t *testing.T) {
+	var names []string
+	for _, d := range Dictionaries() {
+		if !d.Embedded {
+			continue
+		}
+		names = append(names, d.Name)
+
+		source, err := (&InputSourceFactory{}).CreateInputSource("dictionary", &DictionaryConfig{Name: d.Name})
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", d.Name, err)
+		}
+		words, err := source.GetWords()
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", d.Name, err)
+		}
+
+		if len(words) != d.Words {
+			t.Errorf("%s: expected %d words, got %d", d.Name, d.Words, len(words))
+		}
+		if d.Language == "" || d.License == "" {
+			t.Errorf("%s: expected a language and a license, got %+v", d.Name, d)
+		}
+		for _, word := range words {
+			if err := validateWord(0, word.Text); err != nil || word.Text[0] == '#' {
+				t.Errorf("%s: invalid word %q at line %d", d.Name, word.Text, word.Line)
+			}
+		}
+	}
+
+	if expected := []string{"de-basic", "en-basic", "fr-basic"}; !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected embedded dictionaries %v, got %v", expected, names)
+	}
+}
+
+func TestRegisterDictionaries(t *testing.T) {
+	err := RegisterDictionaries(fstest.MapFS{
+		"test-animals.txt": {Data: []byte("# language: la\n# description: Animals\n\ncat\n\nact\n")},
+		"test-plain.txt":   {Data: []byte("dog\ngod\n")},
+		"readme.md":        {Data: []byte("not a dictionary")},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	animals, err := lookupDictionary("test-animals")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if animals.Language != "la" || animals.Description != "Animals" || animals.Words != 2 || animals.Embedded {
+		t.Errorf("unexpected dictionary %+v", animals)
+	}
+
+	plain, err := lookupDictionary("test-plain")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if plain.Language != "test" || plain.Words != 2 {
+		t.Errorf("unexpected dictionary %+v", plain)
+	}
+
+	words, err := NewDictionaryInputSource(animals).GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []Word{
+		{Text: "cat", Source: "dictionary:test-animals", Line: 4, Column: 1},
+		{Text: "act", Source: "dictionary:test-animals", Line: 6, Column: 1},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestRegisterDictionaries_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		fsys fstest.MapFS
+	}{
+		{name: "Already registered", fsys: fstest.MapFS{"en-basic.txt": {Data: []byte("cat\n")}}},
+		{name: "Invalid name", fsys: fstest.MapFS{"Word List.txt": {Data: []byte("cat\n")}}},
+		{name: "Invalid encoding", fsys: fstest.MapFS{"test-latin1.txt": {Data: []byte("caf\xe9\n")}}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if err := RegisterDictionaries(tc.fsys); err == nil {
+				t.Error("expected an error")
+			}
+		})
+	}
+
+	if _, err := lookupDictionary("test-latin1"); !errors.Is(err, ErrUnknownDictionary) {
+		t.Errorf("expected error %v, got %v", ErrUnknownDictionary, err)
+	}
+}
+
+func TestDictionaryInputSource_Unknown(t *testing.T) {
+	_, err := (&InputSourceFactory{}).CreateInputSource("dictionary", &DictionaryConfig{Name: "xx-none"})
+	if !errors.Is(err, ErrUnknownDictionary) {
+		t.Errorf("expected error %v, got %v", ErrUnknownDictionary, err)
+	}
+}
@@ -1,30 +1,46 @@
 package inputsource
 
-import (
-	"fmt"
-	"mime/multipart"
-)
-
 type InputSourceFactoryInterface interface {
-	CreateInputSource(inputType string, inputData interface{}) (InputSource, error)
+	CreateInputSource(inputType string, config Config) (InputSource, error)
 }
 
-type InputSourceFactory struct{}
+type InputSourceFactory struct {
+	// URLPolicy restricts the URLs downloaded by http_url.
+	URLPolicy URLPolicy
+	// URLCache keeps the documents downloaded by http_url, nil disables caching.
+	URLCache URLCache
+	// TempDir is the directory large uploads are spooled to, the default temporary directory when empty.
+	TempDir string
+	// DecompressionLimits guard against decompression bombs in compressed files and downloads.
+	DecompressionLimits DecompressionLimits
+	// FSRoot is the directory the fs input source reads files from, empty disables it.
+	FSRoot string
+	// ObjectStore is the store read by the object_store input source, an empty endpoint disables it.
+	ObjectStore ObjectStore
+	// ObjectStoreBuckets are the buckets of the object store clients may read, none when empty.
+	ObjectStoreBuckets []string
+}
 
 func NewInputSourceFactory() InputSourceFactoryInterface {
 	return &InputSourceFactory{}
 }
 
-func (f *InputSourceFactory) CreateInputSource(inputType string, inputData interface{}) (InputSource, error) {
-	switch inputType {
-	case "http_body":
-		return NewHttpBodyInputSource(inputData.(string)), nil
-	case "http_file":
-		// todo: add error handling for type assertion to avoid potential panics
-		return NewHttpFileInputSource(inputData.(multipart.File)), nil
-	case "http_url":
-		return NewHttpUrlInputSource(inputData.(string)), nil
-	default:
-		return nil, fmt.Errorf("unknown input type: %s", inputType)
+// CreateInputSource creates a registered input source. Errors wrap ErrUnknownSource for an unknown
+// input type and ErrInvalidConfig for a config of the wrong type or failing validation.
+func (f *InputSourceFactory) CreateInputSource(inputType string, config Config) (InputSource, error) {
+	s, err := lookupSource(inputType)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.create(f, config)
+}
+
+// decompressionLimits returns the limits of the input sources, spooling to the temporary directory of the factory.
+func (f *InputSourceFactory) decompressionLimits() DecompressionLimits {
+	limits := f.DecompressionLimits
+	if limits.TempDir == "" {
+		limits.TempDir = f.TempDir
 	}
+	return limits
 }
@@ -0,0 +1,268 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+)
+
+var (
+	ErrFsDisabled = errors.New("filesystem input is disabled")
+	ErrFsPath     = errors.New("path escapes the filesystem root")
+	ErrFsNotFound = errors.New("no file matches the path")
+)
+
+const fsSourceName = "fs"
+
+func init() {
+	Register(fsSourceName,
+		func() *FsConfig { return &FsConfig{} },
+		func(f *InputSourceFactory, c *FsConfig) (InputSource, error) {
+			source, err := NewFsInputSource(f.FSRoot, c.Pattern)
+			if err != nil {
+				return nil, err
+			}
+			source.compression = c.Compression
+			source.charset = c.Charset
+			source.limits = f.decompressionLimits()
+			return source, nil
+		})
+}
+
+// FsConfig holds a path or glob pattern of files below the filesystem root of the factory.
+type FsConfig struct {
+	Pattern string
+	// Compression is the format of the files, detected by their magic bytes when empty.
+	Compression string
+	// Charset is the charset of the files, UTF-8 when empty.
+	Charset string
+}
+
+func (c *FsConfig) UnmarshalText(text []byte) error {
+	c.Pattern = string(text)
+	return nil
+}
+
+func (c *FsConfig) SetCompression(format string) {
+	c.Compression = format
+}
+
+func (c *FsConfig) SetCharset(charset string) {
+	c.Charset = charset
+}
+
+func (c *FsConfig) Validate() error {
+	if c.Pattern == "" {
+		return errors.New("a path is required")
+	}
+	if !filepath.IsLocal(filepath.FromSlash(c.Pattern)) {
+		return fmt.Errorf("%w: %q", ErrFsPath, c.Pattern)
+	}
+	if _, err := filepath.Match(c.Pattern, ""); err != nil {
+		return fmt.Errorf("invalid pattern %q: %v", c.Pattern, err)
+	}
+	if !compressions[c.Compression] {
+		return fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	if _, err := CanonicalCharset(c.Charset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// FsInputSource reads the regular files matching a glob pattern below a root directory, one after
+// another as a single stream. Paths escaping the root, directly or through symbolic links, are refused.
+// Each file is read like an upload and its path relative to the root is reported as its source.
+type FsInputSource struct {
+	root  string
+	paths []string
+
+	compression string
+	charset     string
+	limits      DecompressionLimits
+}
+
+// NewFsInputSource resolves pattern below root, which must be set. The matching files are opened when
+// they are streamed.
+func NewFsInputSource(root, pattern string) (*FsInputSource, error) {
+	if root == "" {
+		return nil, ErrFsDisabled
+	}
+
+	root, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return nil, err
+	}
+	root, err = filepath.Abs(root)
+	if err != nil {
+		return nil, err
+	}
+
+	matches, err := filepath.Glob(filepath.Join(root, filepath.FromSlash(pattern)))
+	if err != nil {
+		return nil, err
+	}
+
+	source := &FsInputSource{root: root}
+	for _, match := range matches {
+		path, err := source.resolve(match)
+		if errors.Is(err, os.ErrNotExist) {
+			// dangling symbolic links
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+		if info.Mode().IsRegular() {
+			source.paths = append(source.paths, match)
+		}
+	}
+
+	if len(source.paths) == 0 {
+		return nil, fmt.Errorf("%w: %q", ErrFsNotFound, pattern)
+	}
+
+	return source, nil
+}
+
+// resolve follows the symbolic links of path and returns the resolved path if it is below the root.
+func (fs *FsInputSource) resolve(path string) (string, error) {
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+
+	rel, err := filepath.Rel(fs.root, resolved)
+	if err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("%w: %q", ErrFsPath, fs.name(path))
+	}
+
+	return resolved, nil
+}
+
+// open opens the file at path if it is below the root. The links are resolved again in case they have been
+// changed since the pattern was matched, and the opened file is checked once more, as the links may also be
+// changed between resolving and opening them.
+func (fs *FsInputSource) open(path string) (*os.File, error) {
+	resolved, err := fs.resolve(path)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(resolved)
+	if err != nil {
+		return nil, err
+	}
+	if err := fs.verify(file, path); err != nil {
+		file.Close()
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// verify checks that the opened file is below the root. Where procfs is available the path the file was
+// opened at is read from it, otherwise the file must be the same as the one path resolves to now.
+func (fs *FsInputSource) verify(file *os.File, path string) error {
+	opened, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if !opened.Mode().IsRegular() {
+		return fmt.Errorf("%w: %q", ErrFsNotFound, fs.name(path))
+	}
+
+	if actual, err := os.Readlink("/proc/self/fd/" + strconv.Itoa(int(file.Fd()))); err == nil {
+		if rel, err := filepath.Rel(fs.root, actual); err != nil || !filepath.IsLocal(rel) {
+			return fmt.Errorf("%w: %q", ErrFsPath, fs.name(path))
+		}
+		return nil
+	}
+
+	resolved, err := fs.resolve(path)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return err
+	}
+	if !os.SameFile(opened, info) {
+		return fmt.Errorf("%w: %q", ErrFsPath, fs.name(path))
+	}
+	return nil
+}
+
+// name returns the path relative to the root with forward slashes.
+func (fs *FsInputSource) name(path string) string {
+	rel, err := filepath.Rel(fs.root, path)
+	if err != nil {
+		return path
+	}
+	return filepath.ToSlash(rel)
+}
+
+// Size returns the total size of the files, -1 if it cannot be determined or a file is compressed, as the
+// size of its content is only known once it is decompressed.
+func (fs *FsInputSource) Size() int64 {
+	var total int64
+	for _, path := range fs.paths {
+		size, err := fs.fileSize(path)
+		if err != nil || size < 0 {
+			return -1
+		}
+		total += size
+	}
+	return total
+}
+
+// fileSize returns the size of the file at path, -1 if it is compressed.
+func (fs *FsInputSource) fileSize(path string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, err
+	}
+	defer file.Close()
+
+	if fileCompression(file, fs.compression) != CompressionPlain {
+		return -1, nil
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return 0, err
+	}
+	return info.Size(), nil
+}
+
+func (fs *FsInputSource) GetWords() ([]Word, error) {
+	return collectWords(fs)
+}
+
+func (fs *FsInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	for _, path := range fs.paths {
+		file, err := fs.open(path)
+		if err != nil {
+			return err
+		}
+
+		source := NewHttpFileInputSource(file, fs.name(path))
+		source.compression = fs.compression
+		source.charset = fs.charset
+		source.limits = fs.limits
+
+		// the file is closed once it has been streamed
+		if err := source.StreamWords(ctx, fn); err != nil {
+			return err
+		}
+	}
+	return nil
+}
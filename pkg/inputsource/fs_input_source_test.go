@@ -0,0 +1,148 @@
+package inputsource
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func writeTestFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestFsInputSource(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+
+	writeTestFile(t, filepath.Join(root, "lists", "north.txt"), "listen\nsilent\n")
+	writeTestFile(t, filepath.Join(root, "lists", "south.txt"), "enlist\n")
+	writeTestFile(t, filepath.Join(root, "lists", "readme.md"), "tinsel\n")
+	writeTestFile(t, filepath.Join(outside, "secret.txt"), "secret\n")
+	os.Mkdir(filepath.Join(root, "lists", "dir.txt"), 0o755)
+
+	if err := os.Symlink(filepath.Join(root, "lists", "south.txt"), filepath.Join(root, "south-link.txt")); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+	os.Symlink(filepath.Join(outside, "secret.txt"), filepath.Join(root, "secret.txt"))
+	os.Symlink(outside, filepath.Join(root, "outside"))
+
+	tests := []struct {
+		name     string
+		pattern  string
+		expected []Word
+		err      error
+	}{
+		{
+			name:    "Glob",
+			pattern: "lists/*.txt",
+			expected: []Word{
+				{Text: "listen", Source: "lists/north.txt", Line: 1, Column: 1},
+				{Text: "silent", Source: "lists/north.txt", Line: 2, Column: 1},
+				{Text: "enlist", Source: "lists/south.txt", Line: 1, Column: 1},
+			},
+		},
+		{
+			name:    "Symbolic link inside the root",
+			pattern: "south-link.txt",
+			expected: []Word{
+				{Text: "enlist", Source: "south-link.txt", Line: 1, Column: 1},
+			},
+		},
+		{name: "Dot dot", pattern: "../secret.txt", err: ErrFsPath},
+		{name: "Absolute path", pattern: filepath.Join(outside, "secret.txt"), err: ErrFsPath},
+		{name: "Symbolic link to a file outside the root", pattern: "secret.txt", err: ErrFsPath},
+		{name: "Symbolic link to a directory outside the root", pattern: "outside/*", err: ErrFsPath},
+		{name: "No match", pattern: "lists/*.csv", err: ErrFsNotFound},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &FsConfig{}
+			config.UnmarshalText([]byte(tc.pattern))
+
+			source, err := (&InputSourceFactory{FSRoot: root}).CreateInputSource("fs", config)
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if tc.err != nil {
+				return
+			}
+
+			words, err := source.GetWords()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("expected words %v, got %v", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestFsInputSource_Disabled(t *testing.T) {
+	config := &FsConfig{Pattern: "words.txt"}
+	if _, err := (&InputSourceFactory{}).CreateInputSource("fs", config); !errors.Is(err, ErrFsDisabled) {
+		t.Errorf("expected error %v, got %v", ErrFsDisabled, err)
+	}
+}
+
+func TestFsInputSource_LinkChangedAfterMatching(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "words.txt"), "cat\n")
+	writeTestFile(t, filepath.Join(outside, "secret.txt"), "secret\n")
+
+	link := filepath.Join(root, "link.txt")
+	if err := os.Symlink(filepath.Join(root, "words.txt"), link); err != nil {
+		t.Skipf("symbolic links are not supported: %v", err)
+	}
+
+	source, err := NewFsInputSource(root, "link.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	os.Remove(link)
+	os.Symlink(filepath.Join(outside, "secret.txt"), link)
+
+	if _, err := source.GetWords(); !errors.Is(err, ErrFsPath) {
+		t.Errorf("expected error %v, got %v", ErrFsPath, err)
+	}
+}
+
+// a link changed between resolving and opening it leads to a file outside the root being opened
+func TestFsInputSource_VerifyOpenedFile(t *testing.T) {
+	root := t.TempDir()
+	outside := t.TempDir()
+	writeTestFile(t, filepath.Join(root, "words.txt"), "cat\n")
+	writeTestFile(t, filepath.Join(outside, "secret.txt"), "secret\n")
+
+	source, err := NewFsInputSource(root, "words.txt")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for name, expected := range map[string]error{
+		filepath.Join(root, "words.txt"):     nil,
+		filepath.Join(outside, "secret.txt"): ErrFsPath,
+	} {
+		file, err := os.Open(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = source.verify(file, filepath.Join(root, "words.txt"))
+		file.Close()
+
+		if !errors.Is(err, expected) {
+			t.Errorf("%s: expected error %v, got %v", name, expected, err)
+		}
+	}
+}
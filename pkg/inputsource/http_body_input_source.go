@@ -1,18 +1,70 @@
 package inputsource
 
 import (
+	"context"
+	"errors"
 	"strings"
 )
 
+const httpBodySourceName = "http_body"
+
+func init() {
+	Register(httpBodySourceName,
+		func() *HttpBodyConfig { return &HttpBodyConfig{} },
+		func(_ *InputSourceFactory, c *HttpBodyConfig) (InputSource, error) {
+			return NewHttpBodyInputSource(c.Data), nil
+		})
+}
+
+// HttpBodyConfig holds comma-separated words sent in the request body.
+type HttpBodyConfig struct {
+	Data string
+}
+
+func (c *HttpBodyConfig) UnmarshalText(text []byte) error {
+	c.Data = string(text)
+	return nil
+}
+
+func (c *HttpBodyConfig) Validate() error {
+	if c.Data == "" || !strings.Contains(c.Data, ",") {
+		return errors.New("at least two comma-separated words are required")
+	}
+	return nil
+}
+
 type HttpBodyInputSource struct {
-	words []string
+	words []Word
+	size  int64
 }
 
 func NewHttpBodyInputSource(inputData string) *HttpBodyInputSource {
-	words := strings.Split(inputData, ",")
-	return &HttpBodyInputSource{words: words}
+	fields := strings.Split(inputData, ",")
+
+	words := make([]Word, len(fields))
+	for i, field := range fields {
+		words[i] = Word{Text: field, Source: httpBodySourceName, Line: 1, Column: i + 1}
+	}
+
+	return &HttpBodyInputSource{words: words, size: int64(len(inputData))}
 }
 
-func (h *HttpBodyInputSource) GetWords() ([]string, error) {
+func (h *HttpBodyInputSource) Size() int64 {
+	return h.size
+}
+
+func (h *HttpBodyInputSource) GetWords() ([]Word, error) {
 	return h.words, nil
 }
+
+func (h *HttpBodyInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	for _, word := range h.words {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
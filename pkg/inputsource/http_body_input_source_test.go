@@ -36,9 +36,26 @@ func TestHttpBodyInputSource_GetWords(t *testing.T) {
 			if err != nil {
 				t.Errorf("unexpected error: %v", err)
 			}
-			if !reflect.DeepEqual(words, tc.expected) {
+			if !reflect.DeepEqual(Texts(words), tc.expected) {
 				t.Errorf("expected words %v, got %v", tc.expected, words)
 			}
 		})
 	}
 }
+
+func TestHttpBodyInputSource_GetWords_Provenance(t *testing.T) {
+	source := NewHttpBodyInputSource("cat,tac")
+	words, err := source.GetWords()
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := []Word{
+		{Text: "cat", Source: "http_body", Line: 1, Column: 1},
+		{Text: "tac", Source: "http_body", Line: 1, Column: 2},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
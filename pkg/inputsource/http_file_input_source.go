@@ -2,30 +2,200 @@ package inputsource
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
 	"mime/multipart"
+	"runtime"
 )
 
+const httpFileSourceName = "http_file"
+
+func init() {
+	Register(httpFileSourceName,
+		func() *HttpFileConfig { return &HttpFileConfig{} },
+		func(f *InputSourceFactory, c *HttpFileConfig) (InputSource, error) {
+			source := NewHttpFileInputSource(c.File, c.Name)
+			source.spoolDir = f.TempDir
+			source.compression = c.Compression
+			source.limits = f.decompressionLimits()
+			source.charset = c.Charset
+			return source, nil
+		})
+}
+
+// FileConfig is implemented by configs of input sources reading an uploaded file.
+type FileConfig interface {
+	SetFile(file multipart.File, name string)
+}
+
+// HttpFileConfig holds an uploaded file and its name.
+type HttpFileConfig struct {
+	File multipart.File
+	Name string
+	// Compression is the format of the file, detected by its magic bytes when empty.
+	Compression string
+	// Charset is the charset of the file, UTF-8 when empty.
+	Charset string
+}
+
+func (c *HttpFileConfig) SetCharset(charset string) {
+	c.Charset = charset
+}
+
+func (c *HttpFileConfig) SetFile(file multipart.File, name string) {
+	c.File = file
+	c.Name = name
+}
+
+func (c *HttpFileConfig) SetCompression(format string) {
+	c.Compression = format
+}
+
+func (c *HttpFileConfig) Validate() error {
+	if c.File == nil {
+		return errors.New("a file is required")
+	}
+	if !compressions[c.Compression] {
+		return fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	if _, err := CanonicalCharset(c.Charset); err != nil {
+		return err
+	}
+	return nil
+}
+
 type HttpFileInputSource struct {
 	file multipart.File
+	name string
+	// spoolDir is where large uploads are copied before they are memory-mapped, the default temporary directory when empty.
+	spoolDir string
+	// parallelThreshold is the size from which the file is ingested in parallel.
+	parallelThreshold int64
+	// compression is the format of the file, detected when empty.
+	compression string
+	limits      DecompressionLimits
+	// scan tokenizes the file, one word per line with parallel ingestion of large files when nil.
+	scan scanFunc
+	// charset is the charset of the file, UTF-8 when empty, a byte order mark takes precedence.
+	charset string
+}
+
+// NewHttpFileInputSource creates an input source reading one word per line from file.
+// The name is reported as the source of the words, http_file is used when it is empty.
+// The file is closed once its words have been read.
+func NewHttpFileInputSource(file multipart.File, name string) *HttpFileInputSource {
+	if name == "" {
+		name = httpFileSourceName
+	}
+	return &HttpFileInputSource{file: file, name: name, parallelThreshold: parallelIngestThreshold}
+}
+
+// Size returns the size of the file, -1 if it cannot be determined or the file is compressed, as the
+// size of its content is only known once it is decompressed.
+func (hf *HttpFileInputSource) Size() int64 {
+	if fileCompression(hf.file, hf.compression) != CompressionPlain {
+		return -1
+	}
+
+	size, err := hf.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return -1
+	}
+	if _, err := hf.file.Seek(0, io.SeekStart); err != nil {
+		return -1
+	}
+	return size
 }
 
-func NewHttpFileInputSource(file multipart.File) *HttpFileInputSource {
-	return &HttpFileInputSource{file: file}
+// Close closes the file of a source that will not be streamed.
+func (hf *HttpFileInputSource) Close() error {
+	return hf.file.Close()
 }
 
-func (hf *HttpFileInputSource) GetWords() ([]string, error) {
+func (hf *HttpFileInputSource) GetWords() ([]Word, error) {
+	return collectWords(hf)
+}
+
+// StreamWords streams the lines of the file. Files of at least 32MB are spooled to disk,
+// memory-mapped and tokenized in parallel, smaller files are read with a single scanner.
+// Compressed files and archives are decompressed and every text member is read in turn.
+func (hf *HttpFileInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
 	defer hf.file.Close()
 
-	var words []string
+	size, err := hf.file.Seek(0, io.SeekEnd)
+	if err != nil {
+		return err
+	}
+	if _, err := hf.file.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	compression := fileCompression(hf.file, hf.compression)
+
+	scan := hf.scan
+	if scan == nil {
+		scan = scanFileLines
+	}
+
+	if compression != CompressionPlain {
+		return readMembers(hf.file, hf.file, size, hf.name, compression, hf.limits, func(name string, r io.Reader) error {
+			return scan(ctx, decodeCharset(r, hf.charset, name), name, fn)
+		})
+	}
+
+	// only UTF-8 files are ingested in parallel, other charsets are transcoded while they are scanned
+	header := make([]byte, 3)
+	n, _ := hf.file.ReadAt(header, 0)
+	charset, err := inputCharset(header[:n], hf.charset)
+	if err != nil {
+		return err
+	}
+
+	if hf.scan == nil && charset == CharsetUTF8 && size >= hf.parallelThreshold {
+		return hf.streamParallel(ctx, size, fn)
+	}
+
+	return scan(ctx, decodeCharset(hf.file, hf.charset, hf.name), hf.name, fn)
+}
+
+// scanFileLines passes every line of r to fn as a word.
+func scanFileLines(ctx context.Context, r io.Reader, source string, fn WordFunc) error {
+	scanner := bufio.NewScanner(r)
+	buf := make([]byte, 0, bufio.MaxScanTokenSize)
+	scanner.Buffer(buf, maxBufSize)
+
+	for line := 1; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := validateLine(scanner.Bytes(), source, line); err != nil {
+			return err
+		}
+		if err := fn(Word{Text: scanner.Text(), Source: source, Line: line, Column: 1}); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
 
-	scanner := bufio.NewScanner(hf.file)
-	for scanner.Scan() {
-		words = append(words, scanner.Text())
+func (hf *HttpFileInputSource) streamParallel(ctx context.Context, size int64, fn WordFunc) error {
+	file, cleanup, err := spoolFile(hf.file, hf.spoolDir)
+	if err != nil {
+		return err
 	}
+	defer cleanup()
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	data, unmap, err := mmapFile(file, size)
+	if err != nil {
+		return err
 	}
+	defer unmap()
+
+	data = bytes.TrimPrefix(data, []byte{0xef, 0xbb, 0xbf})
 
-	return words, nil
+	return ingestParallel(ctx, data, hf.name, ingestChunkSize, runtime.GOMAXPROCS(0), fn)
 }
@@ -58,7 +58,7 @@ func TestHttpFileInputSource_GetWords(t *testing.T) {
 	for _, tc := range tests {
 		t.Run(tc.name, func(t *testing.T) {
 			mockFile := NewMockMultipartFile(tc.fileContents)
-			source := NewHttpFileInputSource(mockFile)
+			source := NewHttpFileInputSource(mockFile, "words.txt")
 			words, err := source.GetWords()
 
 			if err != nil {
@@ -73,3 +73,68 @@ func TestHttpFileInputSource_GetWords(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpFileInputSource_GetWords_Provenance(t *testing.T) {
+	tests := []struct {
+		name     string
+		fileName string
+		expected []Word
+	}{
+		{
+			name:     "Named file",
+			fileName: "words.txt",
+			expected: []Word{
+				{Text: "listen", Source: "words.txt", Line: 1, Column: 1},
+				{Text: "silent", Source: "words.txt", Line: 2, Column: 1},
+			},
+		},
+		{
+			name:     "Unnamed file",
+			fileName: "",
+			expected: []Word{
+				{Text: "listen", Source: "http_file", Line: 1, Column: 1},
+				{Text: "silent", Source: "http_file", Line: 2, Column: 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := NewHttpFileInputSource(NewMockMultipartFile("listen\nsilent"), tc.fileName)
+			words, err := source.GetWords()
+
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("expected words %v, got %v", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestHttpFileInputSource_Size(t *testing.T) {
+	compressed := string(gzipData(t, []byte("listen\nsilent\n")))
+
+	if size := NewHttpFileInputSource(NewMockMultipartFile("listen\nsilent\n"), "").Size(); size != 14 {
+		t.Errorf("expected size 14, got %d", size)
+	}
+	if size := NewHttpFileInputSource(NewMockMultipartFile(compressed), "").Size(); size != -1 {
+		t.Errorf("expected the size of a compressed file to be unknown, got %d", size)
+	}
+}
+
+func TestHttpFileInputSource_SpoolDir(t *testing.T) {
+	dir := t.TempDir()
+	config := &HttpFileConfig{File: NewMockMultipartFile("listen\n"), Name: "words.txt"}
+
+	source, err := (&InputSourceFactory{TempDir: dir}).CreateInputSource("http_file", config)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if spoolDir := source.(*HttpFileInputSource).spoolDir; spoolDir != dir {
+		t.Errorf("expected uploads to be spooled to %s, got %q", dir, spoolDir)
+	}
+}
@@ -0,0 +1,104 @@
+package inputsource
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const httpTextSourceName = "http_text"
+
+func init() {
+	Register(httpTextSourceName,
+		func() *HttpTextConfig { return &HttpTextConfig{} },
+		func(f *InputSourceFactory, c *HttpTextConfig) (InputSource, error) {
+			source := NewHttpTextInputSource(c.Body, c.Size)
+			source.compression = c.Compression
+			source.limits = f.decompressionLimits()
+			source.charset = c.Charset
+			return source, nil
+		})
+}
+
+// BodyConfig is implemented by configs of input sources streaming the request body. size is the
+// length of the body in bytes, -1 if unknown.
+type BodyConfig interface {
+	SetBody(body io.Reader, size int64)
+}
+
+// HttpTextConfig holds a text/plain request body with one word per line.
+type HttpTextConfig struct {
+	Body io.Reader
+	// Size is the length of the body in bytes, -1 if unknown.
+	Size int64
+	// Compression is the format of the body, detected by its magic bytes when empty.
+	Compression string
+	// Charset is the charset of the body, UTF-8 when empty.
+	Charset string
+}
+
+func (c *HttpTextConfig) SetCharset(charset string) {
+	c.Charset = charset
+}
+
+func (c *HttpTextConfig) SetBody(body io.Reader, size int64) {
+	c.Body = body
+	c.Size = size
+}
+
+func (c *HttpTextConfig) SetCompression(format string) {
+	c.Compression = format
+}
+
+func (c *HttpTextConfig) Validate() error {
+	if c.Body == nil {
+		return errors.New("a request body is required")
+	}
+	if !compressions[c.Compression] {
+		return fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	if _, err := CanonicalCharset(c.Charset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// HttpTextInputSource streams the lines of a request body as words without buffering the body.
+type HttpTextInputSource struct {
+	body *bufio.Reader
+	size int64
+	// compression is the format of the body, detected when empty.
+	compression string
+	limits      DecompressionLimits
+	// charset is the charset of the body, UTF-8 when empty, a byte order mark takes precedence.
+	charset string
+}
+
+func NewHttpTextInputSource(body io.Reader, size int64) *HttpTextInputSource {
+	return &HttpTextInputSource{body: bufio.NewReaderSize(body, sniffLen), size: size}
+}
+
+// Size returns the length of the body, -1 if it is unknown or the body is compressed, as the size of
+// its content is only known once it is decompressed.
+func (ht *HttpTextInputSource) Size() int64 {
+	compression := ht.compression
+	if compression == CompressionAuto {
+		compression = detectCompression(peek(ht.body))
+	}
+	if compression != CompressionPlain {
+		return -1
+	}
+	return ht.size
+}
+
+func (ht *HttpTextInputSource) GetWords() ([]Word, error) {
+	return collectWords(ht)
+}
+
+func (ht *HttpTextInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	return readMembers(ht.body, nil, ht.size, httpTextSourceName, ht.compression, ht.limits, func(name string, r io.Reader) error {
+		return scanFileLines(ctx, decodeCharset(r, ht.charset, name), name, fn)
+	})
+}
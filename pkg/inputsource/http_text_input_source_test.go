@@ -0,0 +1,95 @@
+package inputsource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// onceReader can be read only once, like a request body.
+type onceReader struct {
+	r io.Reader
+}
+
+func (o *onceReader) Read(p []byte) (int, error) {
+	return o.r.Read(p)
+}
+
+func TestHttpTextInputSource_GetWords(t *testing.T) {
+	var compressed bytes.Buffer
+	zw := gzip.NewWriter(&compressed)
+	zw.Write([]byte("cat\ntac\n"))
+	zw.Close()
+
+	tests := []struct {
+		name     string
+		body     []byte
+		expected []Word
+	}{
+		{
+			name: "Plain",
+			body: []byte("cat\r\ntac\n"),
+			expected: []Word{
+				{Text: "cat", Source: "http_text", Line: 1, Column: 1},
+				{Text: "tac", Source: "http_text", Line: 2, Column: 1},
+			},
+		},
+		{
+			name: "Gzip",
+			body: compressed.Bytes(),
+			expected: []Word{
+				{Text: "cat", Source: "http_text", Line: 1, Column: 1},
+				{Text: "tac", Source: "http_text", Line: 2, Column: 1},
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &HttpTextConfig{}
+			config.SetBody(&onceReader{r: bytes.NewReader(tc.body)}, int64(len(tc.body)))
+
+			source, err := (&InputSourceFactory{}).CreateInputSource("http_text", config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			words, err := source.GetWords()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("expected words %v, got %v", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestHttpTextInputSource_Size(t *testing.T) {
+	compressed := string(gzipData(t, []byte("cat\n")))
+
+	tests := []struct {
+		name     string
+		body     string
+		size     int64
+		expected int64
+	}{
+		{name: "Unknown", body: "cat\n", size: -1, expected: -1},
+		{name: "Plain", body: "cat\n", size: 4, expected: 4},
+		// the size of compressed content is only known once it is decompressed
+		{name: "Gzip", body: compressed, size: int64(len(compressed)), expected: -1},
+	}
+
+	for _, tc := range tests {
+		source := NewHttpTextInputSource(strings.NewReader(tc.body), tc.size)
+		if size := source.Size(); size != tc.expected {
+			t.Errorf("%s: expected size %d, got %d", tc.name, tc.expected, size)
+		}
+		if words, err := source.GetWords(); err != nil || len(words) != 1 {
+			t.Errorf("%s: expected the word to be read after the size, got %v %v", tc.name, words, err)
+		}
+	}
+}
@@ -0,0 +1,258 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultUrlRetries     = 3
+	defaultRetryBackoff   = 200 * time.Millisecond
+	maxRetryBackoff       = 5 * time.Second
+	defaultRangeThreshold = 8 * 1024 * 1024 // 8MB
+	defaultRangeChunkSize = 4 * 1024 * 1024 // 4MB
+	defaultRangeWorkers   = 4
+	maxRetryAfterSeconds  = 30
+	rangeRequestsInFlight = 2
+)
+
+var ErrUrlChanged = errors.New("url content changed during a ranged download")
+
+// get requests the URL with the given headers. Network errors, 429 and 5xx responses are retried
+// with exponential backoff and full jitter. The last response is returned when all attempts failed
+// with a status, so that it can be reported.
+func (hu *HttpUrlInputSource) get(ctx context.Context, header http.Header) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, hu.url, nil)
+		if err != nil {
+			return nil, err
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+
+		resp, err := hu.client.Do(req)
+		if attempt >= hu.retries || !retryable(ctx, resp, err) {
+			return resp, err
+		}
+
+		wait := hu.backoff(attempt, resp)
+		if resp != nil {
+			io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+			resp.Body.Close()
+		}
+
+		select {
+		case <-time.After(wait):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// retryable reports whether a request failed transiently. Blocked URLs and redirect loops are not retried.
+func retryable(ctx context.Context, resp *http.Response, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	if err != nil {
+		return !errors.Is(err, ErrUrlBlocked) && !errors.Is(err, ErrUrlRedirects)
+	}
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500
+}
+
+// backoff returns a random wait of up to retryBackoff*2^attempt, or the Retry-After of the response.
+func (hu *HttpUrlInputSource) backoff(attempt int, resp *http.Response) time.Duration {
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds >= 0 && seconds <= maxRetryAfterSeconds {
+			return time.Duration(seconds) * time.Second
+		}
+	}
+
+	wait := hu.retryBackoff << attempt
+	if wait <= 0 || wait > maxRetryBackoff {
+		wait = maxRetryBackoff
+	}
+
+	return time.Duration(rand.Int63n(int64(wait) + 1))
+}
+
+// rangeable reports whether the rest of the response can be downloaded in parallel byte ranges.
+func (hu *HttpUrlInputSource) rangeable(resp *http.Response) bool {
+	return resp.StatusCode == http.StatusOK &&
+		strings.Contains(resp.Header.Get("Accept-Ranges"), "bytes") &&
+		resp.ContentLength >= hu.rangeThreshold &&
+		resp.ContentLength <= hu.maxSize
+}
+
+// rangedReader reads a document whose first chunk is read from the initial response while the
+// following chunks are downloaded as byte ranges by several workers. The chunks are read in order,
+// so lines spanning two chunks are stitched by the reader of the document.
+type rangedReader struct {
+	body io.ReadCloser
+	// first reads the first chunk from body, firstSize is its expected size
+	first     io.Reader
+	firstSize int64
+	results   []chan rangeResult
+	current   []byte
+	next      int
+	err       error
+
+	ctx      context.Context
+	inFlight chan struct{}
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+}
+
+type rangeResult struct {
+	data []byte
+	err  error
+}
+
+// newRangedReader reads the first chunk from resp and downloads the rest of the document in ranges.
+// If-Range makes the server respond with the whole document instead of a range if it has changed.
+func (hu *HttpUrlInputSource) newRangedReader(ctx context.Context, resp *http.Response) *rangedReader {
+	ctx, cancel := context.WithCancel(ctx)
+
+	size := resp.ContentLength
+	var starts []int64
+	for start := hu.rangeChunkSize; start < size; start += hu.rangeChunkSize {
+		starts = append(starts, start)
+	}
+
+	r := &rangedReader{
+		body:      resp.Body,
+		first:     io.LimitReader(resp.Body, hu.rangeChunkSize),
+		firstSize: hu.rangeChunkSize,
+		results:   make([]chan rangeResult, len(starts)),
+		ctx:       ctx,
+		inFlight:  make(chan struct{}, rangeRequestsInFlight*hu.rangeWorkers),
+		cancel:    cancel,
+	}
+	for i := range r.results {
+		r.results[i] = make(chan rangeResult, 1)
+	}
+
+	validator := resp.Header.Get("ETag")
+	if validator == "" || strings.HasPrefix(validator, "W/") {
+		validator = resp.Header.Get("Last-Modified")
+	}
+
+	jobs := make(chan int)
+	go func() {
+		defer close(jobs)
+		for i := range starts {
+			select {
+			case r.inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	for w := 0; w < hu.rangeWorkers; w++ {
+		r.wg.Add(1)
+		go func() {
+			defer r.wg.Done()
+			for i := range jobs {
+				end := starts[i] + hu.rangeChunkSize
+				if end > size {
+					end = size
+				}
+				data, err := hu.getRange(ctx, starts[i], end, size, validator)
+				r.results[i] <- rangeResult{data: data, err: err}
+			}
+		}()
+	}
+
+	return r
+}
+
+// getRange downloads the bytes [start, end) of a document of the given size.
+func (hu *HttpUrlInputSource) getRange(ctx context.Context, start, end, size int64, validator string) ([]byte, error) {
+	header := http.Header{}
+	header.Set("Range", fmt.Sprintf("bytes=%d-%d", start, end-1))
+	if validator != "" {
+		header.Set("If-Range", validator)
+	}
+
+	resp, err := hu.get(ctx, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusPartialContent ||
+		resp.Header.Get("Content-Range") != fmt.Sprintf("bytes %d-%d/%d", start, end-1, size) {
+		return nil, fmt.Errorf("%w: %s", ErrUrlChanged, resp.Status)
+	}
+
+	data := make([]byte, end-start)
+	if _, err := io.ReadFull(resp.Body, data); err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
+
+func (r *rangedReader) Read(p []byte) (int, error) {
+	if r.first != nil {
+		n, err := r.first.Read(p)
+		r.firstSize -= int64(n)
+		if err == io.EOF {
+			if r.firstSize > 0 {
+				return n, io.ErrUnexpectedEOF
+			}
+			// the rest of the initial response is not needed
+			r.body.Close()
+			r.first = nil
+			err = nil
+		}
+		return n, err
+	}
+
+	for len(r.current) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		if r.next == len(r.results) {
+			return 0, io.EOF
+		}
+
+		select {
+		case result := <-r.results[r.next]:
+			<-r.inFlight
+			r.next++
+			r.current, r.err = result.data, result.err
+		case <-r.ctx.Done():
+			return 0, r.ctx.Err()
+		}
+	}
+
+	n := copy(p, r.current)
+	r.current = r.current[n:]
+
+	return n, nil
+}
+
+// Close stops the downloads and waits for the workers, which never block on their buffered results.
+func (r *rangedReader) Close() error {
+	r.cancel()
+	r.body.Close()
+	r.wg.Wait()
+
+	return nil
+}
@@ -0,0 +1,147 @@
+package inputsource
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestHttpUrlInputSource_Retries(t *testing.T) {
+	tests := []struct {
+		name             string
+		failures         int
+		expectedErr      error
+		expectedRequests int
+	}{
+		{
+			name:             "transient failures",
+			failures:         2,
+			expectedRequests: 3,
+		},
+		{
+			name:             "retries exhausted",
+			failures:         10,
+			expectedErr:      ErrUrlStatus,
+			expectedRequests: defaultUrlRetries + 1,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= tc.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					return
+				}
+				fmt.Fprint(w, "listen\nsilent\n")
+			}))
+			defer server.Close()
+
+			source := NewHttpUrlInputSource(server.URL, testUrlPolicy, nil)
+			source.retryBackoff = time.Millisecond
+
+			words, err := source.GetWords()
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if err == nil && len(words) != 2 {
+				t.Errorf("expected 2 words, got %v", words)
+			}
+			if requests != tc.expectedRequests {
+				t.Errorf("expected %d requests, got %d", tc.expectedRequests, requests)
+			}
+		})
+	}
+}
+
+func TestHttpUrlInputSource_RangedDownload(t *testing.T) {
+	var content bytes.Buffer
+	var expected []string
+	for i := 0; i < 500; i++ {
+		word := fmt.Sprintf("word%d", i*7919%1000)
+		fmt.Fprintln(&content, word)
+		expected = append(expected, word)
+	}
+
+	tests := []struct {
+		name        string
+		changed     bool
+		flaky       bool
+		expectedErr error
+	}{
+		{
+			name: "unchanged document",
+		},
+		{
+			name:  "flaky range requests",
+			flaky: true,
+		},
+		{
+			name:        "document changed during the download",
+			changed:     true,
+			expectedErr: ErrUrlChanged,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var mu sync.Mutex
+			ranges := 0
+			attempts := make(map[string]int)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				defer mu.Unlock()
+
+				etag := `"v1"`
+				if r.Header.Get("Range") != "" {
+					ranges++
+					attempts[r.Header.Get("Range")]++
+					if tc.flaky && attempts[r.Header.Get("Range")] == 1 {
+						w.WriteHeader(http.StatusBadGateway)
+						return
+					}
+					if tc.changed {
+						etag = `"v2"`
+					}
+				}
+
+				w.Header().Set("ETag", etag)
+				w.Header().Set("Content-Type", "text/plain")
+				http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(content.Bytes()))
+			}))
+			defer server.Close()
+
+			source := NewHttpUrlInputSource(server.URL, testUrlPolicy, nil)
+			source.retryBackoff = time.Millisecond
+			source.rangeThreshold = 1024
+			source.rangeChunkSize = 333
+			source.rangeWorkers = 3
+
+			words, err := source.GetWords()
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Fatalf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			// workers of a failed download may still be requesting ranges
+			mu.Lock()
+			ranged := ranges
+			mu.Unlock()
+			if ranged == 0 {
+				t.Errorf("expected ranged requests")
+			}
+			if err == nil && !reflect.DeepEqual(Texts(words), expected) {
+				t.Errorf("expected %d words in order, got %d", len(expected), len(words))
+			}
+		})
+	}
+}
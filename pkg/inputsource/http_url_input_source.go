@@ -2,41 +2,348 @@ package inputsource
 
 import (
 	"bufio"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
 	"net/http"
+	"net/url"
 	"strings"
+	"time"
+	"unicode"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
+)
+
+var (
+	ErrUrlStatus      = errors.New("url responded with a non-2xx status")
+	ErrUrlContentType = errors.New("url content type is not text")
+	ErrUrlTooLarge    = errors.New("url content exceeds the maximum download size")
+	ErrUrlTimeout     = errors.New("url download timed out")
 )
 
+// HttpUrlInputSource reads one word per line from a text document downloaded from a URL.
+// The download is bounded by a timeout and a maximum size and restricted by a URLPolicy.
+// With a cache, documents served with an ETag or Last-Modified header are kept tokenized
+// and requested again conditionally. Transient failures are retried and large documents of
+// servers accepting byte ranges are downloaded in parallel ranges.
 type HttpUrlInputSource struct {
-	url string
+	url     string
+	client  *http.Client
+	maxSize int64
+	cache   URLCache
+
+	retries      int
+	retryBackoff time.Duration
+	// documents of at least rangeThreshold bytes are downloaded in chunks of rangeChunkSize by rangeWorkers
+	rangeThreshold int64
+	rangeChunkSize int64
+	rangeWorkers   int
+
+	// compression is the format of the document, detected when empty.
+	compression string
+	limits      DecompressionLimits
+
+	// scan tokenizes the document, one word per line when nil. scanKey identifies it in the cache.
+	scan    scanFunc
+	scanKey string
+	// charset is the charset given by the client, the charset parameter of the content type is used when empty.
+	charset string
+}
+
+const (
+	maxBufSize = 2 * 1024 * 1024 // 2MB
+
+	defaultUrlTimeout      = 30 * time.Second
+	defaultMaxDownloadSize = 64 * 1024 * 1024 // 64MB
+)
+
+func init() {
+	Register("http_url",
+		func() *HttpUrlConfig { return &HttpUrlConfig{} },
+		func(f *InputSourceFactory, c *HttpUrlConfig) (InputSource, error) {
+			source := NewHttpUrlInputSource(c.URL, f.URLPolicy, f.URLCache)
+			if c.Cache == CacheBypass {
+				source.cache = nil
+			}
+			source.compression = c.Compression
+			source.limits = f.decompressionLimits()
+			source.charset = c.Charset
+			return source, nil
+		})
+}
+
+// HttpUrlConfig holds the URL of a document with one word per line.
+type HttpUrlConfig struct {
+	URL string
+	// Cache is either CacheDefault or CacheBypass.
+	Cache string
+	// Compression is the format of the document, detected by its magic bytes when empty.
+	Compression string
+	// Charset is the charset of the document, taken from its content type when empty.
+	Charset string
+}
+
+func (c *HttpUrlConfig) SetCharset(charset string) {
+	c.Charset = charset
+}
+
+func (c *HttpUrlConfig) SetCache(mode string) {
+	c.Cache = mode
+}
+
+func (c *HttpUrlConfig) SetCompression(format string) {
+	c.Compression = format
+}
+
+func (c *HttpUrlConfig) UnmarshalText(text []byte) error {
+	c.URL = string(text)
+	return nil
 }
 
-const maxBufSize = 2 * 1024 * 1024 // 2MB
+func (c *HttpUrlConfig) Validate() error {
+	u, err := url.Parse(c.URL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("an absolute http or https URL is required")
+	}
+	if c.Cache != CacheDefault && c.Cache != CacheBypass {
+		return fmt.Errorf("unknown cache mode %q", c.Cache)
+	}
+	if !compressions[c.Compression] {
+		return fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	if _, err := CanonicalCharset(c.Charset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// NewHttpUrlInputSource creates an input source downloading url if the policy allows it.
+// cache may be nil to always download the document.
+func NewHttpUrlInputSource(url string, policy URLPolicy, cache URLCache) *HttpUrlInputSource {
+	return &HttpUrlInputSource{
+		url:     url,
+		client:  policy.client(defaultUrlTimeout),
+		maxSize: defaultMaxDownloadSize,
+		cache:   cache,
 
-func NewHttpUrlInputSource(url string) *HttpUrlInputSource {
-	return &HttpUrlInputSource{url: url}
+		retries:        defaultUrlRetries,
+		retryBackoff:   defaultRetryBackoff,
+		rangeThreshold: defaultRangeThreshold,
+		rangeChunkSize: defaultRangeChunkSize,
+		rangeWorkers:   defaultRangeWorkers,
+	}
+}
+
+func (hu *HttpUrlInputSource) GetWords() ([]Word, error) {
+	return collectWords(hu)
 }
 
-// todo: add error checks for the response status codes
-func (hu *HttpUrlInputSource) GetWords() ([]string, error) {
-	resp, err := http.Get(hu.url)
+func (hu *HttpUrlInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	if err := hu.streamWords(ctx, fn); err != nil {
+		return hu.downloadError(ctx, err)
+	}
+	return nil
+}
+
+func (hu *HttpUrlInputSource) streamWords(ctx context.Context, fn WordFunc) error {
+	header := http.Header{}
+
+	var cached *CachedDocument
+	if hu.cache != nil {
+		cached, _ = hu.cache.Get(hu.cacheKey())
+	}
+	if cached != nil {
+		if cached.ETag != "" {
+			header.Set("If-None-Match", cached.ETag)
+		}
+		if cached.LastModified != "" {
+			header.Set("If-Modified-Since", cached.LastModified)
+		}
+	}
+
+	resp, err := hu.get(ctx, header)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	defer resp.Body.Close()
 
-	var words []string
+	if resp.StatusCode == http.StatusNotModified && cached != nil {
+		return replayWords(ctx, cached.Words, fn)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%w: %s", ErrUrlStatus, resp.Status)
+	}
+
+	// any content type is accepted when the client names the compression of the document
+	mediaType, params, err := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	explicit := hu.compression != CompressionAuto && hu.compression != CompressionPlain
+	if !explicit && (err != nil || (!strings.HasPrefix(mediaType, "text/") && !archiveMediaTypes[mediaType])) {
+		return fmt.Errorf("%w: %q", ErrUrlContentType, resp.Header.Get("Content-Type"))
+	}
 
-	scanner := bufio.NewScanner(resp.Body)
+	if resp.ContentLength > hu.maxSize {
+		return ErrUrlTooLarge
+	}
+
+	body := io.Reader(resp.Body)
+	if hu.rangeable(resp) {
+		ranged := hu.newRangedReader(ctx, resp)
+		defer ranged.Close()
+		body = ranged
+	}
+
+	// documents without validators cannot be requested conditionally and are not cached
+	var doc *CachedDocument
+	var docSize int64
+	var builder *anagram.SignatureBuilder
+	if hu.cache != nil && (resp.Header.Get("ETag") != "" || resp.Header.Get("Last-Modified") != "") {
+		doc = &CachedDocument{ETag: resp.Header.Get("ETag"), LastModified: resp.Header.Get("Last-Modified")}
+		docSize = doc.size()
+		builder = anagram.NewSignatureBuilder(true)
+	}
+
+	charset := hu.charset
+	if charset == "" {
+		charset = params["charset"]
+	}
+
+	body = &limitedReader{r: body, remaining: hu.maxSize, err: ErrUrlTooLarge}
+	scan := hu.scan
+	if scan == nil {
+		scan = scanUrlLines
+	}
+	err = readMembers(body, nil, -1, hu.sourceName(), hu.compression, hu.limits, func(name string, r io.Reader) error {
+		return scan(ctx, decodeCharset(r, charset, name), name, func(word Word) error {
+			if doc != nil {
+				word.Signature = builder.Signature(word.Text)
+				doc.Words = append(doc.Words, word)
+
+				// documents the cache would not keep are not held in memory until the end of the download
+				if docSize += cachedWordSize(word); docSize > hu.cache.MaxEntrySize() {
+					doc = nil
+				}
+			}
+			return fn(word)
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	if doc != nil {
+		hu.cache.Put(hu.cacheKey(), doc)
+	}
+
+	return nil
+}
+
+// sourceName returns the URL without its userinfo and query, which may hold credentials such as the
+// tokens of presigned URLs, to report it as the source of the words.
+func (hu *HttpUrlInputSource) sourceName() string {
+	u, err := url.Parse(hu.url)
+	if err != nil {
+		return hu.url
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.ForceQuery = false
+	return u.String()
+}
+
+// cacheKey distinguishes documents read with a compression or charset given by the client from
+// detected ones and documents tokenized differently, such as CSV columns. The URL is hashed, as its
+// userinfo and query may hold credentials which must not be kept by the cache.
+func (hu *HttpUrlInputSource) cacheKey() string {
+	sum := sha256.Sum256([]byte(hu.url))
+	key := hex.EncodeToString(sum[:])
+	if hu.compression != CompressionAuto {
+		key += "#" + hu.compression
+	}
+	if hu.scanKey != "" {
+		key += "#" + hu.scanKey
+	}
+	if hu.charset != "" {
+		key += "#" + hu.charset
+	}
+	return key
+}
+
+// scanUrlLines passes every line of r to fn as a word, trimmed of surrounding white space.
+func scanUrlLines(_ context.Context, r io.Reader, source string, fn WordFunc) error {
+	scanner := bufio.NewScanner(r)
 	buf := make([]byte, 0, bufio.MaxScanTokenSize)
 	scanner.Buffer(buf, maxBufSize)
 
-	for scanner.Scan() {
-		words = append(words, strings.TrimSpace(scanner.Text()))
+	for line := 1; scanner.Scan(); line++ {
+		if err := validateLine(scanner.Bytes(), source, line); err != nil {
+			return err
+		}
+		text := scanner.Text()
+		trimmed := strings.TrimLeftFunc(text, unicode.IsSpace)
+		word := Word{
+			Text:   strings.TrimSpace(trimmed),
+			Source: source,
+			Line:   line,
+			Column: len(text) - len(trimmed) + 1,
+		}
+		if err := fn(word); err != nil {
+			return err
+		}
+	}
+
+	return scanner.Err()
+}
+
+// replayWords passes the words of a cached document to fn.
+func replayWords(ctx context.Context, words []Word, fn WordFunc) error {
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadError reports timeouts of the client as ErrUrlTimeout, cancellation of ctx is returned as is.
+func (hu *HttpUrlInputSource) downloadError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %v", ErrUrlTimeout, err)
+	}
+
+	return err
+}
+
+// limitedReader fails with err once more than remaining bytes have been read.
+type limitedReader struct {
+	r         io.Reader
+	remaining int64
+	err       error
+}
+
+func (l *limitedReader) Read(p []byte) (int, error) {
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
 	}
 
-	if err := scanner.Err(); err != nil {
-		return nil, err
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return 0, l.err
 	}
 
-	return words, nil
+	return n, err
 }
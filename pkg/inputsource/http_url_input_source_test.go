@@ -1,13 +1,20 @@
 package inputsource
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"reflect"
+	"strings"
 	"testing"
+	"time"
 )
 
+// testUrlPolicy allows the loopback address of httptest servers.
+var testUrlPolicy = URLPolicy{AllowedHosts: []string{"127.0.0.1"}}
+
 func TestHttpUrlInputSource_GetWords(t *testing.T) {
 	tests := []struct {
 		name        string
@@ -30,7 +37,7 @@ func TestHttpUrlInputSource_GetWords(t *testing.T) {
 			}))
 			defer server.Close()
 
-			source := NewHttpUrlInputSource(server.URL)
+			source := NewHttpUrlInputSource(server.URL, testUrlPolicy, nil)
 			words, err := source.GetWords()
 
 			if err != nil {
@@ -44,3 +51,122 @@ func TestHttpUrlInputSource_GetWords(t *testing.T) {
 		})
 	}
 }
+
+func TestHttpUrlInputSource_GetWords_Provenance(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "listen\n  silent \n")
+	}))
+	defer server.Close()
+
+	source := NewHttpUrlInputSource(server.URL, testUrlPolicy, nil)
+	words, err := source.GetWords()
+
+	if err != nil {
+		t.Errorf("unexpected error: %v", err)
+		return
+	}
+
+	expected := []Word{
+		{Text: "listen", Source: server.URL, Line: 1, Column: 1},
+		{Text: "silent", Source: server.URL, Line: 2, Column: 3},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestHttpUrlInputSource_GetWords_ProvenanceWithoutCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, _ := r.BasicAuth(); user != "user" || password != "secret" || r.URL.Query().Get("token") != "abc" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprint(w, "listen\n")
+	}))
+	defer server.Close()
+
+	u, _ := url.Parse(server.URL + "/words.txt?token=abc")
+	u.User = url.UserPassword("user", "secret")
+
+	source := NewHttpUrlInputSource(u.String(), testUrlPolicy, nil)
+	words, err := source.GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Word{{Text: "listen", Source: server.URL + "/words.txt", Line: 1, Column: 1}}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestHttpUrlInputSource_GetWords_Errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		handler     http.HandlerFunc
+		expectedErr error
+	}{
+		{
+			name: "not found",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				http.NotFound(w, r)
+			},
+			expectedErr: ErrUrlStatus,
+		},
+		{
+			name: "binary content",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/octet-stream")
+				fmt.Fprint(w, "listen\nsilent\n")
+			},
+			expectedErr: ErrUrlContentType,
+		},
+		{
+			name: "content length above the maximum size",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				fmt.Fprint(w, strings.Repeat("listen\n", 100))
+			},
+			expectedErr: ErrUrlTooLarge,
+		},
+		{
+			name: "chunked content above the maximum size",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "text/plain")
+				for i := 0; i < 100; i++ {
+					fmt.Fprint(w, "listen\n")
+					w.(http.Flusher).Flush()
+				}
+			},
+			expectedErr: ErrUrlTooLarge,
+		},
+		{
+			name: "slow server",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+			},
+			expectedErr: ErrUrlTimeout,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			server := httptest.NewServer(tc.handler)
+			defer server.Close()
+
+			source := NewHttpUrlInputSource(server.URL, testUrlPolicy, nil)
+			source.maxSize = 64
+			source.client.Timeout = 50 * time.Millisecond
+			source.retries = 0
+
+			_, err := source.GetWords()
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
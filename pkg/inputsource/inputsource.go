@@ -1,5 +1,66 @@
 package inputsource
 
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
 type InputSource interface {
-	GetWords() ([]string, error)
+	GetWords() ([]Word, error)
+}
+
+// Sizer is implemented by input sources knowing the size of their input in bytes up front.
+type Sizer interface {
+	Size() int64
+}
+
+// Word is a single word read from an input source along with its provenance.
+type Word struct {
+	Text string
+	// Source names where the word was read from, e.g. the uploaded file name or the URL.
+	Source string
+	// Line is the 1-based line number of the word in the source.
+	Line int
+	// Column is the 1-based column the word starts at. For comma-separated input
+	// it is the 1-based index of the word in the list instead.
+	Column int
+	// Signature is the pre-computed anagram signature of the word, empty if the source did not compute it.
+	Signature string
+}
+
+// Texts returns the text of each word.
+func Texts(words []Word) []string {
+	texts := make([]string, len(words))
+	for i, word := range words {
+		texts[i] = word.Text
+	}
+	return texts
+}
+
+// WordError reports an invalid element of a structured input, such as an element of a JSON array
+// or a line of NDJSON.
+type WordError struct {
+	// Position is the 1-based index of the element in the array or its line number.
+	Position int
+	Reason   string
+}
+
+func (e *WordError) Error() string {
+	return fmt.Sprintf("word %d %s", e.Position, e.Reason)
+}
+
+// validateWord checks a word given as an element of a structured input.
+func validateWord(position int, word string) error {
+	switch {
+	case strings.TrimSpace(word) == "":
+		return &WordError{Position: position, Reason: "is empty"}
+	case !utf8.ValidString(word):
+		return &WordError{Position: position, Reason: "is not valid UTF-8"}
+	case len(word) > maxBufSize:
+		return &WordError{Position: position, Reason: "exceeds the maximum word length"}
+	case strings.ContainsAny(word, "\r\n"):
+		return &WordError{Position: position, Reason: "contains a line break"}
+	}
+	return nil
 }
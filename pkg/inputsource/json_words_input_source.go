@@ -0,0 +1,58 @@
+package inputsource
+
+import (
+	"errors"
+)
+
+const jsonWordsSourceName = "json_words"
+
+func init() {
+	Register(jsonWordsSourceName,
+		func() *JsonWordsConfig { return &JsonWordsConfig{} },
+		func(_ *InputSourceFactory, c *JsonWordsConfig) (InputSource, error) {
+			return NewJsonWordsInputSource(c.Words), nil
+		})
+}
+
+// WordsConfig is implemented by configs of input sources taking the words as a list.
+type WordsConfig interface {
+	SetWords(words []string)
+}
+
+// JsonWordsConfig holds the words sent as a JSON array in the request body.
+type JsonWordsConfig struct {
+	Words []string
+}
+
+func (c *JsonWordsConfig) SetWords(words []string) {
+	c.Words = words
+}
+
+func (c *JsonWordsConfig) Validate() error {
+	if len(c.Words) < 2 {
+		return errors.New("at least two words are required")
+	}
+	for i, word := range c.Words {
+		if err := validateWord(i+1, word); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type JsonWordsInputSource struct {
+	words []Word
+}
+
+func NewJsonWordsInputSource(texts []string) *JsonWordsInputSource {
+	words := make([]Word, len(texts))
+	for i, text := range texts {
+		words[i] = Word{Text: text, Source: jsonWordsSourceName, Line: 1, Column: i + 1}
+	}
+
+	return &JsonWordsInputSource{words: words}
+}
+
+func (j *JsonWordsInputSource) GetWords() ([]Word, error) {
+	return j.words, nil
+}
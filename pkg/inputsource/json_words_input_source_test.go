@@ -0,0 +1,60 @@
+package inputsource
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestJsonWordsConfig_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		words    []string
+		position int
+		wantErr  bool
+	}{
+		{name: "Valid words", words: []string{"a,b", "b,a"}},
+		{name: "Single word", words: []string{"cat"}, wantErr: true},
+		{name: "Empty word", words: []string{"cat", ""}, position: 2, wantErr: true},
+		{name: "Blank word", words: []string{" ", "cat"}, position: 1, wantErr: true},
+		{name: "Invalid UTF-8", words: []string{"cat", "tac", "\xff"}, position: 3, wantErr: true},
+		{name: "Line break", words: []string{"cat", "t\nac"}, position: 2, wantErr: true},
+		{name: "Too long", words: []string{"cat", strings.Repeat("a", maxBufSize+1)}, position: 2, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &JsonWordsConfig{}
+			c.SetWords(tc.words)
+			err := c.Validate()
+
+			if (err != nil) != tc.wantErr {
+				t.Fatalf("expected error %v, got %v", tc.wantErr, err)
+			}
+
+			var wordErr *WordError
+			if errors.As(err, &wordErr) != (tc.position != 0) {
+				t.Fatalf("unexpected word error: %v", err)
+			}
+			if wordErr != nil && wordErr.Position != tc.position {
+				t.Errorf("expected position %d, got %d", tc.position, wordErr.Position)
+			}
+		})
+	}
+}
+
+func TestJsonWordsInputSource_GetWords(t *testing.T) {
+	words, err := NewJsonWordsInputSource([]string{"a,b", "b,a"}).GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Word{
+		{Text: "a,b", Source: "json_words", Line: 1, Column: 1},
+		{Text: "b,a", Source: "json_words", Line: 1, Column: 2},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
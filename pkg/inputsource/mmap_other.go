@@ -0,0 +1,18 @@
+//go:build !unix
+
+package inputsource
+
+import (
+	"io"
+	"os"
+)
+
+// mmapFile reads the whole file into memory on platforms without mmap support.
+func mmapFile(file *os.File, size int64) ([]byte, func() error, error) {
+	data := make([]byte, size)
+	if _, err := io.ReadFull(io.NewSectionReader(file, 0, size), data); err != nil {
+		return nil, nil, err
+	}
+
+	return data, func() error { return nil }, nil
+}
@@ -0,0 +1,67 @@
+package inputsource
+
+import (
+	"context"
+	"io"
+)
+
+// MultiInputSource streams the words of several input sources one after another into a single
+// grouping run. The words keep the source names of their input sources.
+type MultiInputSource struct {
+	sources []InputSource
+}
+
+// NewMultiInputSource combines sources, a single source is returned as is.
+func NewMultiInputSource(sources ...InputSource) InputSource {
+	if len(sources) == 1 {
+		return sources[0]
+	}
+	return &MultiInputSource{sources: sources}
+}
+
+// Size returns the total size of the sources, -1 if the size of any of them is unknown.
+func (m *MultiInputSource) Size() int64 {
+	var total int64
+	for _, source := range m.sources {
+		sizer, ok := source.(Sizer)
+		if !ok {
+			return -1
+		}
+		size := sizer.Size()
+		if size < 0 {
+			return -1
+		}
+		total += size
+	}
+	return total
+}
+
+func (m *MultiInputSource) GetWords() ([]Word, error) {
+	return collectWords(m)
+}
+
+// StreamWords streams the sources in order. When a source fails, the sources not streamed yet are closed.
+func (m *MultiInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	for i, source := range m.sources {
+		if err := StreamWords(ctx, source, fn); err != nil {
+			CloseSources(m.sources[i+1:])
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the sources holding resources, such as uploaded files.
+func (m *MultiInputSource) Close() error {
+	CloseSources(m.sources)
+	return nil
+}
+
+// CloseSources closes the sources implementing io.Closer, for sources that will not be streamed.
+func CloseSources(sources []InputSource) {
+	for _, source := range sources {
+		if closer, ok := source.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
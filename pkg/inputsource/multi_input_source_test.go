@@ -0,0 +1,76 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// closeRecorder records whether the file has been closed.
+type closeRecorder struct {
+	*MockMultipartFile
+	closed bool
+}
+
+func (c *closeRecorder) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestMultiInputSource_GetWords(t *testing.T) {
+	source := NewMultiInputSource(
+		NewHttpFileInputSource(NewMockMultipartFile("listen\nsilent"), "north.txt"),
+		NewHttpFileInputSource(NewMockMultipartFile("enlist"), "south.txt"),
+		NewHttpBodyInputSource("tinsel,cat"),
+	)
+
+	words, err := source.GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Word{
+		{Text: "listen", Source: "north.txt", Line: 1, Column: 1},
+		{Text: "silent", Source: "north.txt", Line: 2, Column: 1},
+		{Text: "enlist", Source: "south.txt", Line: 1, Column: 1},
+		{Text: "tinsel", Source: "http_body", Line: 1, Column: 1},
+		{Text: "cat", Source: "http_body", Line: 1, Column: 2},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+
+	if size := source.(Sizer).Size(); size != int64(len("listen\nsilent")+len("enlist")+len("tinsel,cat")) {
+		t.Errorf("unexpected size %d", size)
+	}
+}
+
+func TestMultiInputSource_ClosesRemainingSources(t *testing.T) {
+	remaining := &closeRecorder{MockMultipartFile: NewMockMultipartFile("cat")}
+	source := NewMultiInputSource(
+		NewNdjsonInputSource(strings.NewReader("42\n"), -1),
+		NewHttpFileInputSource(remaining, "words.txt"),
+	)
+
+	err := StreamWords(context.Background(), source, func(Word) error { return nil })
+
+	var wordErr *WordError
+	if !errors.As(err, &wordErr) {
+		t.Fatalf("expected a word error, got %v", err)
+	}
+	if !remaining.closed {
+		t.Errorf("expected the file not streamed to be closed")
+	}
+	if size := source.(Sizer).Size(); size != -1 {
+		t.Errorf("expected unknown size, got %d", size)
+	}
+}
+
+func TestNewMultiInputSource_Single(t *testing.T) {
+	single := NewHttpBodyInputSource("cat,tac")
+	if source := NewMultiInputSource(single); source != InputSource(single) {
+		t.Errorf("expected the source itself, got %T", source)
+	}
+}
@@ -0,0 +1,99 @@
+package inputsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+)
+
+const ndjsonSourceName = "ndjson"
+
+func init() {
+	Register(ndjsonSourceName,
+		func() *NdjsonConfig { return &NdjsonConfig{} },
+		func(_ *InputSourceFactory, c *NdjsonConfig) (InputSource, error) {
+			return NewNdjsonInputSource(c.Body, c.Size), nil
+		})
+}
+
+// NdjsonConfig holds a request body of newline-delimited JSON with one word as a JSON string per line.
+type NdjsonConfig struct {
+	Body io.Reader
+	// Size is the length of the body in bytes, -1 if unknown.
+	Size int64
+}
+
+func (c *NdjsonConfig) SetBody(body io.Reader, size int64) {
+	c.Body = body
+	c.Size = size
+}
+
+func (c *NdjsonConfig) Validate() error {
+	if c.Body == nil {
+		return errors.New("ndjson body is required")
+	}
+	return nil
+}
+
+// NdjsonInputSource streams the words of an NDJSON body. Blank lines are skipped, every other
+// line must be a JSON string holding a valid word.
+type NdjsonInputSource struct {
+	body io.Reader
+	size int64
+}
+
+func NewNdjsonInputSource(body io.Reader, size int64) *NdjsonInputSource {
+	return &NdjsonInputSource{body: body, size: size}
+}
+
+func (n *NdjsonInputSource) Size() int64 {
+	return n.size
+}
+
+func (n *NdjsonInputSource) GetWords() ([]Word, error) {
+	return collectWords(n)
+}
+
+func (n *NdjsonInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	scanner := bufio.NewScanner(n.body)
+	buf := make([]byte, 0, bufio.MaxScanTokenSize)
+	// a JSON string can escape every byte of the longest word
+	scanner.Buffer(buf, 6*maxBufSize)
+
+	line := 1
+	for ; scanner.Scan(); line++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		data := bytes.TrimSpace(scanner.Bytes())
+		if len(data) == 0 {
+			continue
+		}
+
+		// json.Unmarshal would replace invalid UTF-8 silently
+		if err := validateLine(data, ndjsonSourceName, line); err != nil {
+			return err
+		}
+
+		var text string
+		if err := json.Unmarshal(data, &text); err != nil {
+			return &WordError{Position: line, Reason: "is not a JSON string"}
+		}
+		if err := validateWord(line, text); err != nil {
+			return err
+		}
+
+		if err := fn(Word{Text: text, Source: ndjsonSourceName, Line: line, Column: 1}); err != nil {
+			return err
+		}
+	}
+
+	if errors.Is(scanner.Err(), bufio.ErrTooLong) {
+		return &WordError{Position: line, Reason: "exceeds the maximum word length"}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,54 @@
+package inputsource
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestNdjsonInputSource_GetWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		expected []Word
+		position int
+	}{
+		{
+			name: "Words with blank lines",
+			body: "\"cat\"\r\n\n \"t\\u0061c\" \n",
+			expected: []Word{
+				{Text: "cat", Source: "ndjson", Line: 1, Column: 1},
+				{Text: "tac", Source: "ndjson", Line: 3, Column: 1},
+			},
+		},
+		{name: "Not a string", body: "\"cat\"\n42\n", position: 2},
+		{name: "Invalid JSON", body: "\"cat\n", position: 1},
+		{name: "Empty string", body: "\"cat\"\n\"\"\n", position: 2},
+		{name: "Escaped line break", body: "\"ca\\nt\"\n", position: 1},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			words, err := NewNdjsonInputSource(strings.NewReader(tc.body), int64(len(tc.body))).GetWords()
+
+			if tc.position == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(words, tc.expected) {
+					t.Errorf("expected words %v, got %v", tc.expected, words)
+				}
+				return
+			}
+
+			var wordErr *WordError
+			if !errors.As(err, &wordErr) {
+				t.Fatalf("expected a word error, got %v", err)
+			}
+			if wordErr.Position != tc.position {
+				t.Errorf("expected position %d, got %d", tc.position, wordErr.Position)
+			}
+		})
+	}
+}
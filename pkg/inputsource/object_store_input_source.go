@@ -0,0 +1,350 @@
+package inputsource
+
+import (
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrObjectStoreDisabled = errors.New("object store input is disabled")
+	ErrObjectStoreStatus   = errors.New("object store responded with a non-2xx status")
+	ErrObjectNotFound      = errors.New("no object matches the key")
+	ErrObjectStoreBucket   = errors.New("bucket is not allowed")
+	ErrObjectStoreTimeout  = errors.New("object store request timed out")
+	ErrObjectTooLarge      = errors.New("object exceeds the maximum size")
+	ErrPrefixTooLarge      = errors.New("prefix exceeds the maximum number of objects or total size")
+)
+
+const objectStoreSourceName = "object_store"
+
+func init() {
+	Register(objectStoreSourceName,
+		func() *ObjectStoreConfig { return &ObjectStoreConfig{} },
+		func(f *InputSourceFactory, c *ObjectStoreConfig) (InputSource, error) {
+			source, err := NewObjectStoreInputSource(f.ObjectStore, c.Bucket, c.Key)
+			if err != nil {
+				return nil, err
+			}
+			if !allowedBucket(f.ObjectStoreBuckets, c.Bucket) {
+				return nil, fmt.Errorf("%w: %q", ErrObjectStoreBucket, c.Bucket)
+			}
+			source.compression = c.Compression
+			source.charset = c.Charset
+			source.limits = f.decompressionLimits()
+			return source, nil
+		})
+}
+
+// ObjectStore is an S3-compatible object store addressed path-style, as endpoint/bucket/key.
+type ObjectStore struct {
+	// Endpoint is the base URL of the store, such as https://s3.eu-west-1.amazonaws.com.
+	Endpoint string
+	// Region is the region requests are signed for, us-east-1 when empty.
+	Region      string
+	Credentials Credentials
+}
+
+// ObjectStoreConfig holds the bucket and key of an object, or a prefix of keys ending with a slash
+// or an asterisk, read as text with one word per line.
+type ObjectStoreConfig struct {
+	Bucket string
+	Key    string
+	// Compression is the format of the objects, detected by their magic bytes when empty.
+	Compression string
+	// Charset is the charset of the objects, the charset parameter of their content type is used when empty.
+	Charset string
+}
+
+// UnmarshalText reads the bucket and key from bucket/key.
+func (c *ObjectStoreConfig) UnmarshalText(text []byte) error {
+	c.Bucket, c.Key, _ = strings.Cut(string(text), "/")
+	return nil
+}
+
+func (c *ObjectStoreConfig) SetCompression(format string) {
+	c.Compression = format
+}
+
+func (c *ObjectStoreConfig) SetCharset(charset string) {
+	c.Charset = charset
+}
+
+func (c *ObjectStoreConfig) Validate() error {
+	if !validBucket(c.Bucket) {
+		return fmt.Errorf("invalid bucket %q", c.Bucket)
+	}
+	if !compressions[c.Compression] {
+		return fmt.Errorf("unknown compression %q", c.Compression)
+	}
+	if _, err := CanonicalCharset(c.Charset); err != nil {
+		return err
+	}
+	return nil
+}
+
+// allowedBucket reports whether bucket is one of the buckets the operator allows clients to read, as the
+// server reads them with its own credentials.
+func allowedBucket(allowed []string, bucket string) bool {
+	for _, b := range allowed {
+		if b == bucket {
+			return true
+		}
+	}
+	return false
+}
+
+// validBucket reports whether name follows the naming rules of S3 buckets.
+func validBucket(name string) bool {
+	if len(name) < 3 || len(name) > 63 {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		if !('a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '.' || c == '-') {
+			return false
+		}
+	}
+	return name[0] != '.' && name[0] != '-' && name[len(name)-1] != '.' && name[len(name)-1] != '-'
+}
+
+// ObjectStoreInputSource reads one word per line from an object of an S3-compatible store, or from
+// every object below a prefix one after another as a single stream. Requests are signed with AWS
+// Signature Version 4. The words of an object report bucket/key as their source.
+type ObjectStoreInputSource struct {
+	store  ObjectStore
+	bucket string
+	key    string
+	// prefix is set when the key is a prefix of the objects to list.
+	prefix bool
+	client *http.Client
+	now    func() time.Time
+	// maxSize is the maximum size of an object in bytes as stored, before it is decompressed.
+	maxSize int64
+	// maxKeys and maxTotalSize limit the number of objects below a prefix and the sum of their sizes as stored.
+	maxKeys      int
+	maxTotalSize int64
+
+	// compression is the format of the objects, detected when empty.
+	compression string
+	limits      DecompressionLimits
+	// charset is the charset given by the client, the charset parameter of the content type is used when empty.
+	charset string
+}
+
+const (
+	defaultObjectStoreTimeout = 30 * time.Second
+	defaultMaxObjectSize      = 64 * 1024 * 1024 // 64MB
+	defaultMaxPrefixKeys      = 1000
+	defaultMaxPrefixSize      = 1024 * 1024 * 1024 // 1GB
+)
+
+// NewObjectStoreInputSource creates a source reading key from bucket. A key ending with a slash or an
+// asterisk, or an empty key, is a prefix and every object below it is read. The store must have an endpoint.
+func NewObjectStoreInputSource(store ObjectStore, bucket, key string) (*ObjectStoreInputSource, error) {
+	if store.Endpoint == "" {
+		return nil, ErrObjectStoreDisabled
+	}
+	if store.Region == "" {
+		store.Region = "us-east-1"
+	}
+
+	source := &ObjectStoreInputSource{
+		store:  store,
+		bucket: bucket,
+		key:    key,
+		client: &http.Client{
+			Timeout: defaultObjectStoreTimeout,
+			Transport: &http.Transport{
+				Proxy:                 http.ProxyFromEnvironment,
+				ResponseHeaderTimeout: defaultObjectStoreTimeout,
+			},
+		},
+		now:          time.Now,
+		maxSize:      defaultMaxObjectSize,
+		maxKeys:      defaultMaxPrefixKeys,
+		maxTotalSize: defaultMaxPrefixSize,
+	}
+	if key == "" || strings.HasSuffix(key, "/") || strings.HasSuffix(key, "*") {
+		source.prefix = true
+		source.key = strings.TrimSuffix(key, "*")
+	}
+
+	return source, nil
+}
+
+// Size returns -1 as the size of the objects is only known once they are requested.
+func (o *ObjectStoreInputSource) Size() int64 {
+	return -1
+}
+
+func (o *ObjectStoreInputSource) GetWords() ([]Word, error) {
+	return collectWords(o)
+}
+
+func (o *ObjectStoreInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	keys := []string{o.key}
+	if o.prefix {
+		var err error
+		if keys, err = o.list(ctx); err != nil {
+			return err
+		}
+		if len(keys) == 0 {
+			return fmt.Errorf("%w: %q", ErrObjectNotFound, o.bucket+"/"+o.key+"*")
+		}
+	}
+
+	// the listed sizes of a prefix are checked up front, the objects are limited to the remaining total
+	// as well in case they changed since
+	remaining := o.maxSize
+	if o.prefix {
+		remaining = o.maxTotalSize
+	}
+	for _, key := range keys {
+		read, err := o.streamObject(ctx, key, remaining, fn)
+		if err != nil {
+			return err
+		}
+		remaining -= read
+	}
+	return nil
+}
+
+// streamObject reads the words of the object at key, reading at most remaining bytes, and returns the
+// number of bytes read.
+func (o *ObjectStoreInputSource) streamObject(ctx context.Context, key string, remaining int64, fn WordFunc) (int64, error) {
+	resp, err := o.get(ctx, "/"+o.bucket+"/"+key, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	limit, limitErr := o.maxSize, ErrObjectTooLarge
+	if remaining < limit {
+		limit, limitErr = remaining, ErrPrefixTooLarge
+	}
+	if resp.ContentLength > limit {
+		return 0, fmt.Errorf("%w: %q", limitErr, o.bucket+"/"+key)
+	}
+	body := &limitedReader{r: resp.Body, remaining: limit, err: limitErr}
+
+	charset := o.charset
+	if charset == "" {
+		charset = MediaTypeCharset(resp.Header.Get("Content-Type"))
+	}
+
+	name := o.bucket + "/" + key
+	err = readMembers(body, nil, resp.ContentLength, name, o.compression, o.limits, func(name string, r io.Reader) error {
+		return scanFileLines(ctx, decodeCharset(r, charset, name), name, fn)
+	})
+	return limit - body.remaining, timeoutError(ctx, err)
+}
+
+// timeoutError reports timeouts of the client as ErrObjectStoreTimeout, cancellation of ctx is returned as is.
+func timeoutError(ctx context.Context, err error) error {
+	var netErr net.Error
+	if ctx.Err() == nil && errors.As(err, &netErr) && netErr.Timeout() {
+		return fmt.Errorf("%w: %v", ErrObjectStoreTimeout, err)
+	}
+	return err
+}
+
+// listBucketResult is the response of ListObjectsV2.
+type listBucketResult struct {
+	Contents []struct {
+		Key  string
+		Size int64
+	}
+	IsTruncated           bool
+	NextContinuationToken string
+}
+
+// list returns the keys of the objects below the prefix with ListObjectsV2, following continuation tokens.
+// Keys ending with a slash, which mark folders, are skipped. The listing stops with ErrPrefixTooLarge as
+// soon as the objects exceed maxKeys or maxTotalSize.
+func (o *ObjectStoreInputSource) list(ctx context.Context) ([]string, error) {
+	var keys []string
+	var size int64
+	query := url.Values{"list-type": {"2"}, "prefix": {o.key}}
+
+	for {
+		resp, err := o.get(ctx, "/"+o.bucket, query)
+		if err != nil {
+			return nil, err
+		}
+
+		var result listBucketResult
+		err = xml.NewDecoder(resp.Body).Decode(&result)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("invalid object listing: %w", err)
+		}
+
+		for _, object := range result.Contents {
+			if strings.HasSuffix(object.Key, "/") {
+				continue
+			}
+			keys = append(keys, object.Key)
+			size += object.Size
+			if len(keys) > o.maxKeys {
+				return nil, fmt.Errorf("%w: %q has more than %d objects", ErrPrefixTooLarge, o.bucket+"/"+o.key+"*", o.maxKeys)
+			}
+			if size > o.maxTotalSize {
+				return nil, fmt.Errorf("%w: %q has more than %d bytes", ErrPrefixTooLarge, o.bucket+"/"+o.key+"*", o.maxTotalSize)
+			}
+		}
+
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return keys, nil
+		}
+		query.Set("continuation-token", result.NextContinuationToken)
+	}
+}
+
+// objectStoreError is the error document of a failed request.
+type objectStoreError struct {
+	Code    string
+	Message string
+}
+
+// get sends a signed GET request for path and returns the response if its status is 2xx.
+// A missing bucket or key is reported as ErrObjectNotFound, other statuses as ErrObjectStoreStatus.
+func (o *ObjectStoreInputSource) get(ctx context.Context, path string, query url.Values) (*http.Response, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(o.store.Endpoint, "/"))
+	if err != nil {
+		return nil, err
+	}
+	endpoint.Path += path
+	endpoint.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	signV4(req, o.store.Credentials, o.store.Region, "s3", o.now())
+
+	resp, err := o.client.Do(req)
+	if err != nil {
+		return nil, timeoutError(ctx, err)
+	}
+	if resp.StatusCode >= 200 && resp.StatusCode <= 299 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	var body objectStoreError
+	xml.NewDecoder(io.LimitReader(resp.Body, 64*1024)).Decode(&body)
+
+	name := strings.TrimPrefix(path, "/")
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, fmt.Errorf("%w: %q: %s", ErrObjectNotFound, name, body.Code)
+	}
+	return nil, fmt.Errorf("%w: %q: %s %s %s", ErrObjectStoreStatus, name, resp.Status, body.Code, body.Message)
+}
@@ -0,0 +1,273 @@
+package inputsource
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testCredentials = Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "secret"}
+
+// fakeObjectStore emulates the GetObject and ListObjectsV2 endpoints of an S3-compatible store addressed
+// path-style. Requests must be signed with testCredentials and listings return at most maxKeys keys per page.
+type fakeObjectStore struct {
+	objects map[string]map[string][]byte
+	maxKeys int
+}
+
+func (s *fakeObjectStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if !s.authorized(r) {
+		s.error(w, http.StatusForbidden, "SignatureDoesNotMatch")
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	objects, ok := s.objects[bucket]
+	if !ok {
+		s.error(w, http.StatusNotFound, "NoSuchBucket")
+		return
+	}
+
+	if key == "" && r.URL.Query().Get("list-type") == "2" {
+		s.list(w, r, objects)
+		return
+	}
+
+	content, ok := objects[key]
+	if !ok {
+		s.error(w, http.StatusNotFound, "NoSuchKey")
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	w.Write(content)
+}
+
+// authorized signs a copy of the request the way the client should have and compares the authorization.
+func (s *fakeObjectStore) authorized(r *http.Request) bool {
+	date, err := time.Parse(sigV4TimeFormat, r.Header.Get("X-Amz-Date"))
+	if err != nil {
+		return false
+	}
+
+	req := httptest.NewRequest(r.Method, "http://"+r.Host+r.URL.RequestURI(), nil)
+	req.Host = ""
+	req.Header = http.Header{}
+	if rng := r.Header.Get("Range"); rng != "" {
+		req.Header.Set("Range", rng)
+	}
+	signV4(req, testCredentials, "us-east-1", "s3", date)
+
+	return req.Header.Get("Authorization") == r.Header.Get("Authorization")
+}
+
+func (s *fakeObjectStore) list(w http.ResponseWriter, r *http.Request, objects map[string][]byte) {
+	prefix := r.URL.Query().Get("prefix")
+	var keys []string
+	for key := range objects {
+		if strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	start, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+	end := start + s.maxKeys
+	truncated := end < len(keys)
+	if !truncated {
+		end = len(keys)
+	}
+
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult xmlns="http://s3.amazonaws.com/doc/2006-03-01/">`)
+	for _, key := range keys[start:end] {
+		b.WriteString("<Contents><Key>")
+		xml.EscapeText(&b, []byte(key))
+		fmt.Fprintf(&b, "</Key><Size>%d</Size></Contents>", len(objects[key]))
+	}
+	fmt.Fprintf(&b, "<KeyCount>%d</KeyCount><IsTruncated>%v</IsTruncated>", end-start, truncated)
+	if truncated {
+		fmt.Fprintf(&b, "<NextContinuationToken>%d</NextContinuationToken>", end)
+	}
+	b.WriteString("</ListBucketResult>")
+
+	w.Header().Set("Content-Type", "application/xml")
+	w.Write([]byte(b.String()))
+}
+
+func (s *fakeObjectStore) error(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?><Error><Code>%s</Code><Message>%s</Message></Error>`, code, code)
+}
+
+func TestObjectStoreInputSource(t *testing.T) {
+	var compressed bytes.Buffer
+	gz := gzip.NewWriter(&compressed)
+	gz.Write([]byte("inlets\n"))
+	gz.Close()
+
+	server := httptest.NewServer(&fakeObjectStore{
+		maxKeys: 2,
+		objects: map[string]map[string][]byte{
+			"corpora": {
+				"en/a.txt":           []byte("listen\nsilent\n"),
+				"en/b.txt":           []byte("enlist\n"),
+				"en/c.txt.gz":        compressed.Bytes(),
+				"en/":                nil,
+				"en/word list+1.txt": []byte("tinsel\n"),
+				"fr/a.txt":           []byte("chien\nniche\n"),
+				"big/words.txt":      bytes.Repeat([]byte("listen\n"), 100),
+			},
+		},
+	})
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		input       string
+		credentials Credentials
+		// maxKeys and maxTotalSize override the limits of prefixes when set.
+		maxKeys      int
+		maxTotalSize int64
+		expected     []Word
+		err          error
+	}{
+		{
+			name:  "Object",
+			input: "corpora/en/a.txt",
+			expected: []Word{
+				{Text: "listen", Source: "corpora/en/a.txt", Line: 1, Column: 1},
+				{Text: "silent", Source: "corpora/en/a.txt", Line: 2, Column: 1},
+			},
+		},
+		{
+			name:  "Key with reserved characters",
+			input: "corpora/en/word list+1.txt",
+			expected: []Word{
+				{Text: "tinsel", Source: "corpora/en/word list+1.txt", Line: 1, Column: 1},
+			},
+		},
+		{
+			name:  "Prefix across pages",
+			input: "corpora/en/",
+			expected: []Word{
+				{Text: "listen", Source: "corpora/en/a.txt", Line: 1, Column: 1},
+				{Text: "silent", Source: "corpora/en/a.txt", Line: 2, Column: 1},
+				{Text: "enlist", Source: "corpora/en/b.txt", Line: 1, Column: 1},
+				{Text: "inlets", Source: "corpora/en/c.txt", Line: 1, Column: 1},
+				{Text: "tinsel", Source: "corpora/en/word list+1.txt", Line: 1, Column: 1},
+			},
+		},
+		{
+			name:  "Prefix with an asterisk",
+			input: "corpora/f*",
+			expected: []Word{
+				{Text: "chien", Source: "corpora/fr/a.txt", Line: 1, Column: 1},
+				{Text: "niche", Source: "corpora/fr/a.txt", Line: 2, Column: 1},
+			},
+		},
+		{name: "Missing key", input: "corpora/en/d.txt", err: ErrObjectNotFound},
+		{name: "Missing bucket", input: "archive/en/a.txt", err: ErrObjectNotFound},
+		{name: "Object too large", input: "corpora/big/words.txt", err: ErrObjectTooLarge},
+		{name: "Empty prefix", input: "corpora/de/", err: ErrObjectNotFound},
+		{name: "Prefix with too many objects", input: "corpora/en/", maxKeys: 3, err: ErrPrefixTooLarge},
+		{name: "Prefix too large", input: "corpora/en/", maxTotalSize: 30, err: ErrPrefixTooLarge},
+		{
+			name:         "Prefix within the limits",
+			input:        "corpora/f*",
+			maxKeys:      1,
+			maxTotalSize: 12,
+			expected: []Word{
+				{Text: "chien", Source: "corpora/fr/a.txt", Line: 1, Column: 1},
+				{Text: "niche", Source: "corpora/fr/a.txt", Line: 2, Column: 1},
+			},
+		},
+		{
+			name:        "Wrong credentials",
+			input:       "corpora/en/a.txt",
+			credentials: Credentials{AccessKeyID: "AKIDEXAMPLE", SecretAccessKey: "wrong"},
+			err:         ErrObjectStoreStatus,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			store := ObjectStore{Endpoint: server.URL, Credentials: testCredentials}
+			if tc.credentials != (Credentials{}) {
+				store.Credentials = tc.credentials
+			}
+
+			config := &ObjectStoreConfig{}
+			config.UnmarshalText([]byte(tc.input))
+
+			f := &InputSourceFactory{ObjectStore: store, ObjectStoreBuckets: []string{"corpora", "archive"}}
+			source, err := f.CreateInputSource("object_store", config)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			objectStore := source.(*ObjectStoreInputSource)
+			objectStore.maxSize = 500
+			if tc.maxKeys != 0 {
+				objectStore.maxKeys = tc.maxKeys
+			}
+			if tc.maxTotalSize != 0 {
+				objectStore.maxTotalSize = tc.maxTotalSize
+			}
+
+			words, err := source.GetWords()
+			if !errors.Is(err, tc.err) {
+				t.Fatalf("expected error %v, got %v", tc.err, err)
+			}
+			if tc.err == nil && !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("expected words %v, got %v", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestObjectStoreInputSource_Disabled(t *testing.T) {
+	config := &ObjectStoreConfig{Bucket: "corpora", Key: "en/a.txt"}
+	if _, err := (&InputSourceFactory{}).CreateInputSource("object_store", config); !errors.Is(err, ErrObjectStoreDisabled) {
+		t.Errorf("expected error %v, got %v", ErrObjectStoreDisabled, err)
+	}
+}
+
+func TestObjectStoreInputSource_BucketNotAllowed(t *testing.T) {
+	f := &InputSourceFactory{ObjectStore: ObjectStore{Endpoint: "http://127.0.0.1:9000"}, ObjectStoreBuckets: []string{"corpora"}}
+	config := &ObjectStoreConfig{Bucket: "private", Key: "en/a.txt"}
+	if _, err := f.CreateInputSource("object_store", config); !errors.Is(err, ErrObjectStoreBucket) {
+		t.Errorf("expected error %v, got %v", ErrObjectStoreBucket, err)
+	}
+}
+
+func TestObjectStoreConfig_Validate(t *testing.T) {
+	tests := []struct {
+		input   string
+		wantErr bool
+	}{
+		{input: "corpora/en/a.txt"},
+		{input: "my.corpora-2"},
+		{input: "ab/a.txt", wantErr: true},
+		{input: "Corpora/a.txt", wantErr: true},
+		{input: "-corpora/a.txt", wantErr: true},
+		{input: "/a.txt", wantErr: true},
+	}
+
+	for _, tc := range tests {
+		config := &ObjectStoreConfig{}
+		config.UnmarshalText([]byte(tc.input))
+		if err := config.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("%q: expected error %v, got %v", tc.input, tc.wantErr, err)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package inputsource
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"mime/multipart"
+	"os"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/onurdemirkale/anagram-finder/pkg/anagram"
+)
+
+const (
+	// parallelIngestThreshold is the upload size from which files are memory-mapped and tokenized in parallel.
+	parallelIngestThreshold = 32 * 1024 * 1024 // 32MB
+	ingestChunkSize         = 4 * 1024 * 1024  // 4MB
+)
+
+// spoolFile returns an *os.File holding the contents of file. Uploads that are already
+// backed by a temporary file are used as is, others are copied into a temporary file in dir.
+// The returned function removes the temporary file.
+func spoolFile(file multipart.File, dir string) (*os.File, func(), error) {
+	if f, ok := file.(*os.File); ok {
+		return f, func() {}, nil
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		return nil, nil, err
+	}
+
+	spool, err := os.CreateTemp(dir, "anagram-upload-")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cleanup := func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}
+
+	if _, err := io.Copy(spool, file); err != nil {
+		cleanup()
+		return nil, nil, err
+	}
+
+	return spool, cleanup, nil
+}
+
+// ingestParallel splits data into newline-aligned chunks, tokenizes the chunks and computes the
+// signatures of their words on several goroutines, then passes the words to fn in input order.
+// At most twice as many chunks as workers are held in memory at once.
+func ingestParallel(ctx context.Context, data []byte, source string, chunkSize, workers int, fn WordFunc) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	chunks := splitChunks(data, chunkSize)
+	results := make([]chan tokenizedChunk, len(chunks))
+	for i := range results {
+		results[i] = make(chan tokenizedChunk, 1)
+	}
+
+	jobs := make(chan int)
+	inFlight := make(chan struct{}, 2*workers)
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			builder := anagram.NewSignatureBuilder(true)
+			for i := range jobs {
+				results[i] <- tokenizeChunk(chunks[i], source, builder)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(jobs)
+		for i := range chunks {
+			select {
+			case inFlight <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+
+			select {
+			case jobs <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	// workers read from data which may be unmapped once we return
+	defer wg.Wait()
+	defer cancel()
+
+	line := 0
+	for i := range chunks {
+		var result tokenizedChunk
+
+		select {
+		case result = <-results[i]:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+		<-inFlight
+
+		if result.err != nil {
+			return result.err
+		}
+		if result.invalidLine > 0 {
+			return &EncodingError{Source: source, Charset: CharsetUTF8, Line: line + result.invalidLine}
+		}
+
+		words := result.words
+		for _, word := range words {
+			word.Line += line
+			if err := fn(word); err != nil {
+				return err
+			}
+		}
+
+		line += len(words)
+	}
+
+	return nil
+}
+
+// splitChunks splits data into chunks of about chunkSize bytes, each ending after a newline.
+func splitChunks(data []byte, chunkSize int) [][]byte {
+	var chunks [][]byte
+
+	for len(data) > 0 {
+		end := len(data)
+		if chunkSize < len(data) {
+			if i := bytes.IndexByte(data[chunkSize:], '\n'); i >= 0 {
+				end = chunkSize + i + 1
+			}
+		}
+
+		chunks = append(chunks, data[:end])
+		data = data[end:]
+	}
+
+	return chunks
+}
+
+// tokenizedChunk holds the words of a chunk, or the line of the chunk that is not valid UTF-8, or the
+// error of a line longer than the lines read by scanFileLines.
+type tokenizedChunk struct {
+	words       []Word
+	invalidLine int
+	err         error
+}
+
+// tokenizeChunk returns a word for every line of the chunk, line numbers are relative to the chunk.
+// Lines are split the same way as bufio.ScanLines does.
+func tokenizeChunk(chunk []byte, source string, builder *anagram.SignatureBuilder) tokenizedChunk {
+	words := make([]Word, 0, bytes.Count(chunk, []byte{'\n'})+1)
+
+	for line := 1; len(chunk) > 0; line++ {
+		text := chunk
+		if i := bytes.IndexByte(chunk, '\n'); i >= 0 {
+			text, chunk = chunk[:i], chunk[i+1:]
+		} else {
+			chunk = nil
+		}
+
+		// the same lines are accepted whether a file is ingested in parallel or not
+		if len(text) >= maxBufSize {
+			return tokenizedChunk{err: bufio.ErrTooLong}
+		}
+
+		text = bytes.TrimSuffix(text, []byte{'\r'})
+		if !utf8.Valid(text) {
+			return tokenizedChunk{invalidLine: line}
+		}
+		word := string(text)
+
+		words = append(words, Word{
+			Text:      word,
+			Source:    source,
+			Line:      line,
+			Column:    1,
+			Signature: builder.Signature(word),
+		})
+	}
+
+	return tokenizedChunk{words: words}
+}
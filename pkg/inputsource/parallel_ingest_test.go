@@ -0,0 +1,140 @@
+package inputsource
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIngestParallel(t *testing.T) {
+	var lines []string
+	for i := 0; i < 1000; i++ {
+		lines = append(lines, fmt.Sprintf("word%d", i))
+	}
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{name: "Trailing newline", content: strings.Join(lines, "\n") + "\n"},
+		{name: "No trailing newline", content: strings.Join(lines, "\n")},
+		{name: "CRLF and empty lines", content: "listen\r\n\r\nsilent\n\nenlist\r\n"},
+		{name: "Empty", content: ""},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			expected, err := NewHttpFileInputSource(NewMockMultipartFile(tc.content), "words.txt").GetWords()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			for _, chunkSize := range []int{1, 7, 64, len(tc.content) + 1} {
+				var actual []Word
+				err := ingestParallel(context.Background(), []byte(tc.content), "words.txt", chunkSize, 4, func(word Word) error {
+					if word.Signature == "" && strings.TrimSpace(word.Text) != "" {
+						t.Errorf("expected signature for %q", word.Text)
+					}
+					word.Signature = ""
+					actual = append(actual, word)
+					return nil
+				})
+
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				if !reflect.DeepEqual(actual, expected) {
+					t.Errorf("chunk size %d: expected words %v, got %v", chunkSize, expected, actual)
+				}
+			}
+		})
+	}
+}
+
+// lines are limited the same way as by scanFileLines, so a file is accepted whether it is ingested in parallel or not
+func TestIngestParallel_LongLines(t *testing.T) {
+	for _, length := range []int{maxBufSize - 1, maxBufSize} {
+		content := "listen\n" + strings.Repeat("a", length) + "\nsilent\n"
+
+		_, scanErr := NewHttpFileInputSource(NewMockMultipartFile(content), "words.txt").GetWords()
+		parallelErr := ingestParallel(context.Background(), []byte(content), "words.txt", 64, 4, func(Word) error { return nil })
+
+		if errors.Is(scanErr, bufio.ErrTooLong) != (length >= maxBufSize) {
+			t.Errorf("line of %d bytes: unexpected error of the scanner %v", length, scanErr)
+		}
+		if errors.Is(parallelErr, bufio.ErrTooLong) != errors.Is(scanErr, bufio.ErrTooLong) {
+			t.Errorf("line of %d bytes: expected error %v, got %v", length, scanErr, parallelErr)
+		}
+	}
+}
+
+func TestIngestParallel_Stop(t *testing.T) {
+	content := strings.Repeat("word\n", 10000)
+	errStop := errors.New("stop")
+
+	count := 0
+	err := ingestParallel(context.Background(), []byte(content), "words.txt", 16, 4, func(word Word) error {
+		count++
+		if count == 100 {
+			return errStop
+		}
+		return nil
+	})
+
+	if !errors.Is(err, errStop) {
+		t.Errorf("expected error %v, got %v", errStop, err)
+	}
+	if count != 100 {
+		t.Errorf("expected the stream to stop after 100 words, got %d", count)
+	}
+}
+
+func TestHttpFileInputSource_GetWords_Parallel(t *testing.T) {
+	content := "listen\nsilent\nenlist\n"
+	expected := []Word{
+		{Text: "listen", Source: "words.txt", Line: 1, Column: 1, Signature: "eilnst"},
+		{Text: "silent", Source: "words.txt", Line: 2, Column: 1, Signature: "eilnst"},
+		{Text: "enlist", Source: "words.txt", Line: 3, Column: 1, Signature: "eilnst"},
+	}
+
+	file, err := os.CreateTemp(t.TempDir(), "upload-")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	file.WriteString(content)
+
+	tests := []struct {
+		name   string
+		source *HttpFileInputSource
+	}{
+		{name: "Spooled upload", source: NewHttpFileInputSource(NewMockMultipartFile(content), "words.txt")},
+		{name: "File backed upload", source: NewHttpFileInputSource(file, "words.txt")},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tc.source.spoolDir = t.TempDir()
+			tc.source.parallelThreshold = 1
+
+			words, err := tc.source.GetWords()
+			if err != nil {
+				t.Errorf("unexpected error: %v", err)
+				return
+			}
+
+			if !reflect.DeepEqual(words, expected) {
+				t.Errorf("expected words %v, got %v", expected, words)
+			}
+
+			entries, _ := os.ReadDir(tc.source.spoolDir)
+			if len(entries) != 0 {
+				t.Errorf("expected spooled file to be removed")
+			}
+		})
+	}
+}
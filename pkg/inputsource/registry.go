@@ -0,0 +1,97 @@
+package inputsource
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+)
+
+var (
+	ErrUnknownSource = errors.New("unknown input source")
+	ErrInvalidConfig = errors.New("invalid input source configuration")
+)
+
+// Config is the typed configuration of an input source. Configs that can be given as the text
+// of a request also implement encoding.TextUnmarshaler.
+type Config interface {
+	Validate() error
+}
+
+// CachingConfig is implemented by configs of input sources with a cache, mode is either
+// CacheDefault or CacheBypass.
+type CachingConfig interface {
+	SetCache(mode string)
+}
+
+type source struct {
+	newConfig func() Config
+	create    func(f *InputSourceFactory, config Config) (InputSource, error)
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]source)
+)
+
+// Register makes an input source available under its name. newConfig returns an empty config of
+// the source, newSource creates the source configured by the factory from a config that has been
+// validated. It panics if the name is registered twice.
+func Register[C Config](name string, newConfig func() C, newSource func(f *InputSourceFactory, config C) (InputSource, error)) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[name]; ok {
+		panic("inputsource: Register called twice for source " + name)
+	}
+
+	registry[name] = source{
+		newConfig: func() Config { return newConfig() },
+		create: func(f *InputSourceFactory, config Config) (InputSource, error) {
+			c, ok := config.(C)
+			if !ok {
+				return nil, fmt.Errorf("%w: %s does not accept %T", ErrInvalidConfig, name, config)
+			}
+			if err := c.Validate(); err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidConfig, err)
+			}
+			return newSource(f, c)
+		},
+	}
+}
+
+func lookupSource(name string) (source, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	s, ok := registry[name]
+	if !ok {
+		return source{}, fmt.Errorf("%w: %s", ErrUnknownSource, name)
+	}
+
+	return s, nil
+}
+
+// NewConfig returns an empty config of the input source registered under the name.
+func NewConfig(name string) (Config, error) {
+	s, err := lookupSource(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.newConfig(), nil
+}
+
+// SourceNames returns the names of the registered input sources in sorted order.
+func SourceNames() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -0,0 +1,86 @@
+package inputsource
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestSourceNames(t *testing.T) {
+	expected := []string{"csv", "dictionary", "fs", "http_body", "http_file", "http_text", "http_url", "json_words", "ndjson", "object_store"}
+
+	if actual := SourceNames(); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %v, got %v", expected, actual)
+	}
+}
+
+func TestInputSourceFactory_CreateInputSource(t *testing.T) {
+	tests := []struct {
+		name        string
+		inputType   string
+		config      Config
+		expectedErr error
+	}{
+		{
+			name:      "Body config",
+			inputType: "http_body",
+			config:    &HttpBodyConfig{Data: "cat,tac"},
+		},
+		{
+			name:      "Url config",
+			inputType: "http_url",
+			config:    &HttpUrlConfig{URL: "https://example.com/words.txt"},
+		},
+		{
+			name:        "Unknown input type",
+			inputType:   "ftp",
+			config:      &HttpBodyConfig{Data: "cat,tac"},
+			expectedErr: ErrUnknownSource,
+		},
+		{
+			name:        "Config of another source",
+			inputType:   "http_file",
+			config:      &HttpBodyConfig{Data: "cat,tac"},
+			expectedErr: ErrInvalidConfig,
+		},
+		{
+			name:        "Single word",
+			inputType:   "http_body",
+			config:      &HttpBodyConfig{Data: "cat"},
+			expectedErr: ErrInvalidConfig,
+		},
+		{
+			name:        "Missing file",
+			inputType:   "http_file",
+			config:      &HttpFileConfig{},
+			expectedErr: ErrInvalidConfig,
+		},
+		{
+			name:        "Relative url",
+			inputType:   "http_url",
+			config:      &HttpUrlConfig{URL: "words.txt"},
+			expectedErr: ErrInvalidConfig,
+		},
+		{
+			name:        "Nil config",
+			inputType:   "http_url",
+			config:      nil,
+			expectedErr: ErrInvalidConfig,
+		},
+	}
+
+	factory := NewInputSourceFactory()
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source, err := factory.CreateInputSource(tc.inputType, tc.config)
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+			if tc.expectedErr == nil && source == nil {
+				t.Errorf("expected an input source")
+			}
+		})
+	}
+}
@@ -0,0 +1,83 @@
+package inputsource
+
+import (
+	"context"
+	"io"
+)
+
+// WordFunc is called for every word of a stream. Returning an error stops the stream
+// and the error is returned to the caller of StreamWords.
+type WordFunc func(word Word) error
+
+// scanFunc tokenizes a document read from r into words, reporting source as their provenance.
+type scanFunc func(ctx context.Context, r io.Reader, source string, fn WordFunc) error
+
+// StreamingInputSource yields its words one at a time instead of building the whole
+// list in memory. fn is called synchronously, a slow consumer slows down the source.
+type StreamingInputSource interface {
+	InputSource
+	StreamWords(ctx context.Context, fn WordFunc) error
+}
+
+// StreamWords streams the words of the input source to fn.
+// Sources that do not implement StreamingInputSource are adapted through GetWords.
+func StreamWords(ctx context.Context, source InputSource, fn WordFunc) error {
+	if ss, ok := source.(StreamingInputSource); ok {
+		return ss.StreamWords(ctx, fn)
+	}
+
+	words, err := source.GetWords()
+	if err != nil {
+		return err
+	}
+
+	for _, word := range words {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := fn(word); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WordChannel streams the words of the input source through a channel with the given buffer size.
+// The error channel receives the result of the stream once the word channel is closed.
+// Cancel ctx to stop the producer when the consumer stops reading early.
+func WordChannel(ctx context.Context, source InputSource, size int) (<-chan Word, <-chan error) {
+	words := make(chan Word, size)
+	errc := make(chan error, 1)
+
+	go func() {
+		defer close(errc)
+		defer close(words)
+
+		errc <- StreamWords(ctx, source, func(word Word) error {
+			select {
+			case words <- word:
+				return nil
+			case <-ctx.Done():
+				return ctx.Err()
+			}
+		})
+	}()
+
+	return words, errc
+}
+
+// collectWords implements the GetWords contract for streaming sources.
+func collectWords(source StreamingInputSource) ([]Word, error) {
+	var words []Word
+
+	err := source.StreamWords(context.Background(), func(word Word) error {
+		words = append(words, word)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return words, nil
+}
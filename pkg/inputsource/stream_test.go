@@ -0,0 +1,100 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+)
+
+type sliceInputSource struct {
+	words []Word
+}
+
+func (s *sliceInputSource) GetWords() ([]Word, error) {
+	return s.words, nil
+}
+
+func TestStreamWords(t *testing.T) {
+	errStop := errors.New("stop")
+
+	tests := []struct {
+		name          string
+		source        InputSource
+		stopAfter     int
+		expected      []string
+		expectedError error
+	}{
+		{
+			name:     "Streaming source",
+			source:   NewHttpFileInputSource(NewMockMultipartFile("cat\ntac\nact"), ""),
+			expected: []string{"cat", "tac", "act"},
+		},
+		{
+			name:     "GetWords adapter",
+			source:   &sliceInputSource{words: []Word{{Text: "cat"}, {Text: "tac"}}},
+			expected: []string{"cat", "tac"},
+		},
+		{
+			name:          "Consumer stops the stream",
+			source:        NewHttpBodyInputSource("cat,tac,act"),
+			stopAfter:     2,
+			expected:      []string{"cat", "tac"},
+			expectedError: errStop,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			var words []string
+
+			err := StreamWords(context.Background(), tc.source, func(word Word) error {
+				words = append(words, word.Text)
+				if len(words) == tc.stopAfter {
+					return errStop
+				}
+				return nil
+			})
+
+			if !errors.Is(err, tc.expectedError) {
+				t.Errorf("expected error %v, got %v", tc.expectedError, err)
+			}
+			if !reflect.DeepEqual(words, tc.expected) {
+				t.Errorf("expected words %v, got %v", tc.expected, words)
+			}
+		})
+	}
+}
+
+func TestStreamWords_Canceled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	source := NewHttpFileInputSource(NewMockMultipartFile("cat\ntac"), "")
+	err := StreamWords(ctx, source, func(word Word) error {
+		t.Errorf("unexpected word %v", word)
+		return nil
+	})
+
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+}
+
+func TestWordChannel(t *testing.T) {
+	words, errc := WordChannel(context.Background(), NewHttpBodyInputSource("cat,tac,act"), 1)
+
+	var actual []string
+	for word := range words {
+		actual = append(actual, word.Text)
+	}
+
+	if err := <-errc; err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := []string{"cat", "tac", "act"}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected words %v, got %v", expected, actual)
+	}
+}
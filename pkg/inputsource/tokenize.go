@@ -0,0 +1,321 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// TokenizeOptions split the words of an input source as running text. The zero value leaves the words as is.
+type TokenizeOptions struct {
+	// Enabled splits every word read from the source on Unicode word boundaries.
+	Enabled bool
+	// DropPunctuation drops the segments holding neither letters nor digits.
+	DropPunctuation bool
+	// DropNumbers drops the segments holding digits but no letters.
+	DropNumbers bool
+	// StripHtml removes markup, comments, scripts and styles and decodes character references first.
+	StripHtml bool
+}
+
+func (o TokenizeOptions) Validate() error {
+	if !o.Enabled && (o.DropPunctuation || o.DropNumbers || o.StripHtml) {
+		return errors.New("tokenize options require tokenizing")
+	}
+	return nil
+}
+
+// TokenizingInputSource splits the words of an input source, typically lines of prose or HTML, into
+// the words they contain. The provenance of a word is the line of the source word and the column of
+// the segment within it.
+type TokenizingInputSource struct {
+	source  InputSource
+	options TokenizeOptions
+}
+
+// NewTokenizingInputSource wraps source, it returns source itself when the options do not enable tokenizing.
+func NewTokenizingInputSource(source InputSource, options TokenizeOptions) InputSource {
+	if !options.Enabled {
+		return source
+	}
+	return &TokenizingInputSource{source: source, options: options}
+}
+
+// Size returns the size of the wrapped source, -1 if it is unknown.
+func (t *TokenizingInputSource) Size() int64 {
+	if sizer, ok := t.source.(Sizer); ok {
+		return sizer.Size()
+	}
+	return -1
+}
+
+func (t *TokenizingInputSource) GetWords() ([]Word, error) {
+	return collectWords(t)
+}
+
+func (t *TokenizingInputSource) StreamWords(ctx context.Context, fn WordFunc) error {
+	// the markup state carries over from one word to the next as tags may span lines
+	var stripper htmlStripper
+
+	return StreamWords(ctx, t.source, func(word Word) error {
+		text := word.Text
+		if t.options.StripHtml {
+			text = stripper.strip(text)
+		}
+
+		for _, segment := range segmentWords(text, t.options.StripHtml) {
+			if t.options.DropPunctuation && segment.kind == segmentPunctuation {
+				continue
+			}
+			if t.options.DropNumbers && segment.kind == segmentNumber {
+				continue
+			}
+
+			err := fn(Word{
+				Text:   segment.text,
+				Source: word.Source,
+				Line:   word.Line,
+				Column: word.Column + segment.offset,
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+type segmentKind int
+
+const (
+	segmentWord segmentKind = iota
+	segmentNumber
+	segmentPunctuation
+)
+
+// segment is a word, a number or a punctuation character of running text at a byte offset.
+type segment struct {
+	text   string
+	offset int
+	kind   segmentKind
+}
+
+// char is a rune of running text at the byte offset it was read from.
+type char struct {
+	r      rune
+	offset int
+}
+
+// segmentWords splits text on word boundaries following the rules of Unicode Standard Annex #29
+// for letters and digits: letters and digits form words together, apostrophes, middle dots and
+// colons join letters, commas and periods join digits and underscores join both. Ideographs are
+// words of their own and other characters, except white space, are punctuation. With references,
+// character references are decoded, the decoded characters keep the offset of the reference.
+func segmentWords(text string, references bool) []segment {
+	chars := decodeChars(text, references)
+
+	var segments []segment
+	for i := 0; i < len(chars); {
+		c := chars[i]
+		switch {
+		case unicode.IsSpace(c.r):
+			i++
+		case isWordChar(c.r) && !isIdeograph(c.r):
+			j := i + 1
+			for j < len(chars) {
+				switch {
+				case unicode.Is(unicode.M, chars[j].r) || (isWordChar(chars[j].r) && !isIdeograph(chars[j].r)):
+					j++
+					continue
+				case j+1 < len(chars) && joins(chars[j-1].r, chars[j].r, chars[j+1].r):
+					j += 2
+					continue
+				}
+				break
+			}
+			segments = append(segments, newSegment(chars[i:j]))
+			i = j
+		default:
+			// ideographs, punctuation and symbols are segments of a single character and its marks
+			j := i + 1
+			for j < len(chars) && unicode.Is(unicode.M, chars[j].r) {
+				j++
+			}
+			segments = append(segments, newSegment(chars[i:j]))
+			i = j
+		}
+	}
+
+	return segments
+}
+
+func newSegment(chars []char) segment {
+	var b strings.Builder
+	kind := segmentPunctuation
+	for _, c := range chars {
+		b.WriteRune(c.r)
+		switch {
+		case unicode.IsLetter(c.r):
+			kind = segmentWord
+		case unicode.IsDigit(c.r) && kind == segmentPunctuation:
+			kind = segmentNumber
+		}
+	}
+	return segment{text: b.String(), offset: chars[0].offset, kind: kind}
+}
+
+func isWordChar(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Pc, r)
+}
+
+func isIdeograph(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana)
+}
+
+// joins reports whether the middle rune joins the runes before and after it into one word.
+func joins(before, middle, after rune) bool {
+	switch middle {
+	case '\'', '’', '·', '‧', ':', '.':
+		if unicode.IsLetter(before) && unicode.IsLetter(after) {
+			return true
+		}
+	}
+	switch middle {
+	case ',', '.', ';', '\'', '’':
+		return unicode.IsDigit(before) && unicode.IsDigit(after)
+	}
+	return false
+}
+
+// decodeChars returns the runes of text, decoding character references such as &amp; when asked.
+func decodeChars(text string, references bool) []char {
+	chars := make([]char, 0, len(text))
+	for offset := 0; offset < len(text); {
+		if references && text[offset] == '&' {
+			if end := strings.IndexByte(text[offset:], ';'); end > 1 && end <= 32 {
+				reference := text[offset : offset+end+1]
+				if decoded := html.UnescapeString(reference); decoded != reference {
+					for _, r := range decoded {
+						chars = append(chars, char{r: r, offset: offset})
+					}
+					offset += end + 1
+					continue
+				}
+			}
+		}
+
+		r, size := utf8.DecodeRuneInString(text[offset:])
+		chars = append(chars, char{r: r, offset: offset})
+		offset += size
+	}
+	return chars
+}
+
+type htmlState int
+
+const (
+	htmlText htmlState = iota
+	htmlTag
+	htmlComment
+	htmlRawText
+)
+
+// htmlStripper removes the markup of an HTML document read line by line. Markup is replaced with
+// spaces, keeping the offsets of the text, and the content of scripts and styles is dropped.
+type htmlStripper struct {
+	state htmlState
+	// quote is the quote of the attribute value the tag is in, 0 outside values.
+	quote byte
+	// tag collects the name of the tag being read while inName, rawEnd is the end tag closing a script or style.
+	tag    strings.Builder
+	inName bool
+	rawEnd string
+}
+
+func (s *htmlStripper) strip(text string) string {
+	out := []byte(text)
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch s.state {
+		case htmlText:
+			if c != '<' || i+1 >= len(text) {
+				continue
+			}
+			next := text[i+1]
+			switch {
+			case strings.HasPrefix(text[i:], "<!--"):
+				s.state = htmlComment
+				blank(out, i, i+4)
+				i += 3
+				continue
+			case isASCIILetter(next) || next == '/' || next == '!' || next == '?':
+				s.state = htmlTag
+				s.quote = 0
+				s.tag.Reset()
+				s.inName = true
+			default:
+				continue
+			}
+			out[i] = ' '
+
+		case htmlTag:
+			out[i] = ' '
+			switch {
+			case s.quote != 0:
+				if c == s.quote {
+					s.quote = 0
+				}
+			case c == '"' || c == '\'':
+				s.quote = c
+				s.inName = false
+			case c == '>':
+				s.state = htmlText
+				name := strings.ToLower(s.tag.String())
+				if (name == "script" || name == "style") && !strings.HasSuffix(text[:i], "/") {
+					s.state = htmlRawText
+					s.rawEnd = "</" + name
+				}
+			case s.inName && (isASCIILetter(c) || c == '/' && s.tag.Len() == 0):
+				// the slash of end tags is part of the name, they never open raw text
+				s.tag.WriteByte(c)
+			default:
+				s.inName = false
+			}
+
+		case htmlComment:
+			out[i] = ' '
+			if strings.HasPrefix(text[i:], "-->") {
+				blank(out, i, i+3)
+				i += 2
+				s.state = htmlText
+			}
+
+		case htmlRawText:
+			if len(text)-i >= len(s.rawEnd) && strings.EqualFold(text[i:i+len(s.rawEnd)], s.rawEnd) {
+				// the end tag is read as a tag
+				s.state = htmlTag
+				s.quote = 0
+				s.tag.Reset()
+				s.inName = false
+			}
+			out[i] = ' '
+		}
+	}
+
+	return string(out)
+}
+
+func blank(out []byte, from, to int) {
+	for i := from; i < to && i < len(out); i++ {
+		out[i] = ' '
+	}
+}
+
+func isASCIILetter(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
@@ -0,0 +1,87 @@
+package inputsource
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenizingInputSource_GetWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		content  string
+		options  TokenizeOptions
+		expected []string
+	}{
+		{
+			name:     "Prose",
+			content:  "Listen, it's silent.\nThe café's 1,000 rooms: 42 in all!",
+			options:  TokenizeOptions{Enabled: true},
+			expected: []string{"Listen", ",", "it's", "silent", ".", "The", "café's", "1,000", "rooms", ":", "42", "in", "all", "!"},
+		},
+		{
+			name:     "Drop punctuation and numbers",
+			content:  "Listen, it's silent.\nThe café's 1,000 rooms: 42 in all!",
+			options:  TokenizeOptions{Enabled: true, DropPunctuation: true, DropNumbers: true},
+			expected: []string{"Listen", "it's", "silent", "The", "café's", "rooms", "in", "all"},
+		},
+		{
+			name:     "Ideographs and underscores",
+			content:  "漢字 snake_case a1",
+			options:  TokenizeOptions{Enabled: true},
+			expected: []string{"漢", "字", "snake_case", "a1"},
+		},
+		{
+			name: "Html",
+			content: "<html><head><style>p { color: red }</style>\n<script type=\"text/javascript\">\nvar tac = \"<b>\";\n</script></head>\n" +
+				"<body><p class=\"a > b\">Cat &amp; act<br/>tac</p><!-- dog\ngod --></body>",
+			options:  TokenizeOptions{Enabled: true, DropPunctuation: true, StripHtml: true},
+			expected: []string{"Cat", "act", "tac"},
+		},
+		{
+			name:     "Markup kept without stripping",
+			content:  "<b>cat</b>",
+			options:  TokenizeOptions{Enabled: true, DropPunctuation: true},
+			expected: []string{"b", "cat", "b"},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			source := NewTokenizingInputSource(NewHttpFileInputSource(NewMockMultipartFile(tc.content), "text.txt"), tc.options)
+			words, err := source.GetWords()
+
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(Texts(words), tc.expected) {
+				t.Errorf("expected words %q, got %q", tc.expected, Texts(words))
+			}
+		})
+	}
+}
+
+func TestTokenizingInputSource_Provenance(t *testing.T) {
+	source := NewTokenizingInputSource(NewHttpFileInputSource(NewMockMultipartFile("cat\n<i>the &lt;tac</i>"), "text.html"),
+		TokenizeOptions{Enabled: true, DropPunctuation: true, StripHtml: true})
+	words, err := source.GetWords()
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []Word{
+		{Text: "cat", Source: "text.html", Line: 1, Column: 1},
+		{Text: "the", Source: "text.html", Line: 2, Column: 4},
+		{Text: "tac", Source: "text.html", Line: 2, Column: 12},
+	}
+	if !reflect.DeepEqual(words, expected) {
+		t.Errorf("expected words %v, got %v", expected, words)
+	}
+}
+
+func TestNewTokenizingInputSource_Disabled(t *testing.T) {
+	source := NewHttpBodyInputSource("cat,tac")
+	if wrapped := NewTokenizingInputSource(source, TokenizeOptions{}); wrapped != InputSource(source) {
+		t.Errorf("expected the source itself, got %T", wrapped)
+	}
+}
@@ -0,0 +1,141 @@
+package inputsource
+
+import (
+	"container/list"
+	"sync"
+)
+
+const (
+	CacheDefault = ""
+	// CacheBypass downloads the URL without reading or updating the cache.
+	CacheBypass = "bypass"
+
+	// cachedWordOverhead approximates the memory used by a cached word besides its strings.
+	cachedWordOverhead = 64
+)
+
+// URLCache keeps the tokenized words of downloaded URLs along with the validators of the response,
+// so that unchanged documents are neither downloaded nor tokenized again. Implementations must be
+// safe for concurrent use.
+type URLCache interface {
+	Get(url string) (*CachedDocument, bool)
+	Put(url string, doc *CachedDocument)
+	// MaxEntrySize returns the size of the largest document the cache keeps, as computed by CachedDocument.
+	// Downloads stop collecting their words for the cache beyond it.
+	MaxEntrySize() int64
+}
+
+// CachedDocument is the tokenized content of a URL. ETag and LastModified are sent back in
+// conditional requests, the words are reused when the server responds 304 Not Modified.
+// The words of a cached document must not be modified.
+type CachedDocument struct {
+	ETag         string
+	LastModified string
+	Words        []Word
+}
+
+func (d *CachedDocument) size() int64 {
+	size := int64(len(d.ETag) + len(d.LastModified))
+	for _, word := range d.Words {
+		size += cachedWordSize(word)
+	}
+	return size
+}
+
+func cachedWordSize(word Word) int64 {
+	return int64(len(word.Text)+len(word.Signature)) + cachedWordOverhead
+}
+
+// MemoryURLCache is a URLCache holding documents in memory, the least recently used documents
+// are evicted once the cache exceeds its size in bytes.
+type MemoryURLCache struct {
+	mu  sync.Mutex
+	lru *lru[*CachedDocument]
+}
+
+func NewMemoryURLCache(maxSize int64) *MemoryURLCache {
+	return &MemoryURLCache{lru: newLRU[*CachedDocument](maxSize, nil)}
+}
+
+func (c *MemoryURLCache) Get(url string) (*CachedDocument, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.lru.get(url)
+}
+
+func (c *MemoryURLCache) Put(url string, doc *CachedDocument) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.lru.put(url, doc, doc.size())
+}
+
+func (c *MemoryURLCache) MaxEntrySize() int64 {
+	return c.lru.maxSize
+}
+
+// lru tracks the size of its entries and evicts the least recently used ones beyond maxSize.
+// It is not safe for concurrent use.
+type lru[V any] struct {
+	maxSize int64
+	size    int64
+	order   *list.List
+	entries map[string]*list.Element
+	// onEvict is called for every entry removed from the cache, it may be nil
+	onEvict func(key string, value V)
+}
+
+type lruEntry[V any] struct {
+	key   string
+	value V
+	size  int64
+}
+
+func newLRU[V any](maxSize int64, onEvict func(key string, value V)) *lru[V] {
+	return &lru[V]{
+		maxSize: maxSize,
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+		onEvict: onEvict,
+	}
+}
+
+func (l *lru[V]) get(key string) (V, bool) {
+	element, ok := l.entries[key]
+	if !ok {
+		var zero V
+		return zero, false
+	}
+
+	l.order.MoveToFront(element)
+	return element.Value.(*lruEntry[V]).value, true
+}
+
+// put adds or replaces an entry, entries larger than the cache are not added.
+func (l *lru[V]) put(key string, value V, size int64) {
+	if element, ok := l.entries[key]; ok {
+		l.remove(element)
+	}
+
+	if size > l.maxSize {
+		return
+	}
+
+	l.entries[key] = l.order.PushFront(&lruEntry[V]{key: key, value: value, size: size})
+	l.size += size
+
+	for l.size > l.maxSize {
+		l.remove(l.order.Back())
+	}
+}
+
+func (l *lru[V]) remove(element *list.Element) {
+	entry := l.order.Remove(element).(*lruEntry[V])
+	delete(l.entries, entry.key)
+	l.size -= entry.size
+
+	if l.onEvict != nil {
+		l.onEvict(entry.key, entry.value)
+	}
+}
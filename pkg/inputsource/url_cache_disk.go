@@ -0,0 +1,142 @@
+package inputsource
+
+import (
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const diskCacheExt = ".gob"
+
+// DiskURLCache is a URLCache storing one file per document in a directory, the least recently
+// used files are removed once they exceed the size of the cache in bytes. Documents stored by
+// a previous process are reused.
+type DiskURLCache struct {
+	dir string
+
+	mu  sync.Mutex
+	lru *lru[string]
+}
+
+// diskDocument is the content of a cache file, the URL guards against hash collisions.
+type diskDocument struct {
+	URL string
+	CachedDocument
+}
+
+func NewDiskURLCache(dir string, maxSize int64) (*DiskURLCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []os.FileInfo
+	for _, entry := range entries {
+		if !strings.HasSuffix(entry.Name(), diskCacheExt) {
+			continue
+		}
+		if info, err := entry.Info(); err == nil {
+			files = append(files, info)
+		}
+	}
+
+	// the most recently used file is added last to end up in front
+	sort.Slice(files, func(i, j int) bool { return files[i].ModTime().Before(files[j].ModTime()) })
+
+	c := &DiskURLCache{dir: dir}
+	c.lru = newLRU[string](maxSize, func(key, path string) { os.Remove(path) })
+
+	for _, file := range files {
+		key := strings.TrimSuffix(file.Name(), diskCacheExt)
+		path := filepath.Join(dir, file.Name())
+		c.lru.put(key, path, file.Size())
+
+		if _, ok := c.lru.entries[key]; !ok {
+			os.Remove(path)
+		}
+	}
+
+	return c, nil
+}
+
+func (c *DiskURLCache) Get(url string) (*CachedDocument, bool) {
+	key := diskCacheKey(url)
+
+	c.mu.Lock()
+	path, ok := c.lru.get(key)
+	c.mu.Unlock()
+
+	if !ok {
+		return nil, false
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, false
+	}
+	defer file.Close()
+
+	var doc diskDocument
+	if err := gob.NewDecoder(file).Decode(&doc); err != nil || doc.URL != url {
+		return nil, false
+	}
+
+	// the modification time keeps the order of use across restarts
+	now := time.Now()
+	os.Chtimes(path, now, now)
+
+	return &doc.CachedDocument, true
+}
+
+func (c *DiskURLCache) Put(url string, doc *CachedDocument) {
+	key := diskCacheKey(url)
+
+	tmp, err := os.CreateTemp(c.dir, "tmp-")
+	if err != nil {
+		return
+	}
+	defer os.Remove(tmp.Name())
+
+	err = gob.NewEncoder(tmp).Encode(diskDocument{URL: url, CachedDocument: *doc})
+	if closeErr := tmp.Close(); err != nil || closeErr != nil {
+		return
+	}
+
+	info, err := os.Stat(tmp.Name())
+	if err != nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// replacing an entry removes its previous file before the new one is moved in place
+	path := filepath.Join(c.dir, key+diskCacheExt)
+	c.lru.put(key, path, info.Size())
+
+	if _, ok := c.lru.get(key); ok {
+		if err := os.Rename(tmp.Name(), path); err != nil {
+			c.lru.remove(c.lru.entries[key])
+		}
+	}
+}
+
+// MaxEntrySize returns the size of the cache, the encoded documents are about as large as their size in memory.
+func (c *DiskURLCache) MaxEntrySize() int64 {
+	return c.lru.maxSize
+}
+
+func diskCacheKey(url string) string {
+	sum := sha256.Sum256([]byte(url))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,191 @@
+package inputsource
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestHttpUrlInputSource_Cache(t *testing.T) {
+	const etag = `"v1"`
+	var downloads, notModified int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("If-None-Match") == etag {
+			notModified++
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		downloads++
+		w.Header().Set("ETag", etag)
+		fmt.Fprint(w, "listen\nsilent\n")
+	}))
+	defer server.Close()
+
+	caches := map[string]URLCache{
+		"memory": NewMemoryURLCache(1 << 20),
+	}
+	diskCache, err := NewDiskURLCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	caches["disk"] = diskCache
+
+	for name, cache := range caches {
+		t.Run(name, func(t *testing.T) {
+			downloads, notModified = 0, 0
+
+			first, err := NewHttpUrlInputSource(server.URL, testUrlPolicy, cache).GetWords()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			second, err := NewHttpUrlInputSource(server.URL, testUrlPolicy, cache).GetWords()
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if downloads != 1 || notModified != 1 {
+				t.Errorf("expected 1 download and 1 conditional request, got %d and %d", downloads, notModified)
+			}
+			if !reflect.DeepEqual(first, second) || second[0].Signature != "eilnst" {
+				t.Errorf("expected cached words %v, got %v", first, second)
+			}
+
+			source := NewHttpUrlInputSource(server.URL, testUrlPolicy, cache)
+			source.cache = nil
+			if _, err := source.GetWords(); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if downloads != 2 {
+				t.Errorf("expected the cache to be bypassed, got %d downloads", downloads)
+			}
+		})
+	}
+}
+
+func TestHttpUrlInputSource_Cache_WithoutValidators(t *testing.T) {
+	downloads := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		downloads++
+		fmt.Fprint(w, "listen\nsilent\n")
+	}))
+	defer server.Close()
+
+	cache := NewMemoryURLCache(1 << 20)
+	for i := 0; i < 2; i++ {
+		if _, err := NewHttpUrlInputSource(server.URL, testUrlPolicy, cache).GetWords(); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if downloads != 2 {
+		t.Errorf("expected 2 downloads, got %d", downloads)
+	}
+}
+
+func TestHttpUrlInputSource_Cache_TooLarge(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, strings.Repeat("listen\nsilent\n", 10))
+	}))
+	defer server.Close()
+
+	// the document exceeds the cache while it is downloaded and its words are dropped
+	cache := NewMemoryURLCache(5 * cachedWordOverhead)
+	source := NewHttpUrlInputSource(server.URL, testUrlPolicy, cache)
+	words, err := source.GetWords()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(words) != 20 {
+		t.Errorf("expected 20 words, got %d", len(words))
+	}
+	if _, ok := cache.Get(source.cacheKey()); ok {
+		t.Errorf("expected the document not to be cached")
+	}
+}
+
+func TestDiskURLCache_WithoutCredentials(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v1"`)
+		fmt.Fprint(w, "listen\nsilent\n")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	cache, err := NewDiskURLCache(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	u := strings.Replace(server.URL, "http://", "http://user:secret@", 1) + "/words.txt?token=tokenxyz"
+	if _, err := NewHttpUrlInputSource(u, testUrlPolicy, cache).GetWords(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	files, _ := os.ReadDir(dir)
+	if len(files) != 1 {
+		t.Fatalf("expected 1 cached document, got %d", len(files))
+	}
+	content, _ := os.ReadFile(filepath.Join(dir, files[0].Name()))
+	if bytes.Contains(content, []byte("secret")) || bytes.Contains(content, []byte("tokenxyz")) {
+		t.Errorf("expected the cached document not to hold the credentials of the url")
+	}
+}
+
+func TestMemoryURLCache_Evicts(t *testing.T) {
+	doc := &CachedDocument{ETag: "a", Words: []Word{{Text: "listen"}}}
+	cache := NewMemoryURLCache(2 * doc.size())
+
+	cache.Put("a", doc)
+	cache.Put("b", doc)
+	cache.Get("a")
+	cache.Put("c", doc)
+
+	for url, expected := range map[string]bool{"a": true, "b": false, "c": true} {
+		if _, ok := cache.Get(url); ok != expected {
+			t.Errorf("expected %s cached %v, got %v", url, expected, ok)
+		}
+	}
+}
+
+func TestDiskURLCache_Reopen(t *testing.T) {
+	dir := t.TempDir()
+	doc := &CachedDocument{ETag: "a", Words: []Word{{Text: "listen", Signature: "eilnst"}}}
+
+	cache, err := NewDiskURLCache(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	cache.Put("http://example.com/words.txt", doc)
+	cache.Put("http://example.com/words.txt", doc)
+
+	reopened, err := NewDiskURLCache(dir, 1<<20)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	actual, ok := reopened.Get("http://example.com/words.txt")
+	if !ok || !reflect.DeepEqual(actual, doc) {
+		t.Errorf("expected %v, got %v", doc, actual)
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 1 {
+		t.Errorf("expected 1 cache file, got %d", len(entries))
+	}
+
+	// a cache too small for the file removes it
+	if _, err := NewDiskURLCache(dir, 1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected the cache file to be evicted, got %d files", len(entries))
+	}
+}
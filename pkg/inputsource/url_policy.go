@@ -0,0 +1,181 @@
+package inputsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+var (
+	ErrUrlBlocked   = errors.New("url targets a blocked host or address")
+	ErrUrlRedirects = errors.New("url exceeded the maximum number of redirects")
+)
+
+const defaultMaxRedirects = 5
+
+// blockedNetworks are ranges not covered by the net.IP predicates checked in blockedIP.
+var blockedNetworks = []*net.IPNet{
+	mustParseCIDR("0.0.0.0/8"),
+	mustParseCIDR("100.64.0.0/10"), // carrier-grade NAT
+	mustParseCIDR("192.0.0.0/24"),
+	mustParseCIDR("198.18.0.0/15"), // benchmarking
+	mustParseCIDR("240.0.0.0/4"),
+	mustParseCIDR("64:ff9b::/96"), // NAT64 embedding IPv4 addresses
+}
+
+// URLPolicy decides which URLs may be downloaded. Hosts are resolved before connecting and every
+// address is checked, so that names pointing to internal addresses are blocked as well. Loopback,
+// private, link-local, multicast and reserved addresses are blocked unless the host is allowed
+// explicitly. The policy is applied again to every redirect.
+type URLPolicy struct {
+	// AllowedHosts restricts downloads to these hosts when it is not empty. Their addresses are not
+	// checked, so internal hosts can be allowed on purpose. "*.example.com" matches example.com and
+	// all of its subdomains, other wildcards are invalid.
+	AllowedHosts []string
+	// DeniedHosts are never downloaded from, even when they are allowed.
+	DeniedHosts []string
+	// MaxRedirects is the number of redirects followed, defaults to 5.
+	MaxRedirects int
+}
+
+// Validate rejects host patterns with wildcards other than a leading "*.".
+func (p URLPolicy) Validate() error {
+	for _, patterns := range [][]string{p.AllowedHosts, p.DeniedHosts} {
+		for _, pattern := range patterns {
+			if strings.Contains(strings.TrimPrefix(pattern, "*."), "*") || pattern == "*." {
+				return fmt.Errorf("invalid host pattern %q, only a leading *. matches subdomains", pattern)
+			}
+		}
+	}
+	return nil
+}
+
+// client returns an HTTP client enforcing the policy with the given timeout.
+// Proxies are not used since they would connect to the checked addresses instead of us.
+func (p URLPolicy) client(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		host, port, err := net.SplitHostPort(address)
+		if err != nil {
+			return nil, err
+		}
+
+		ips, err := p.resolve(ctx, host)
+		if err != nil {
+			return nil, err
+		}
+
+		var conn net.Conn
+		for _, ip := range ips {
+			conn, err = dialer.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+			if err == nil {
+				return conn, nil
+			}
+		}
+		return nil, err
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if len(via) > p.maxRedirects() {
+				return ErrUrlRedirects
+			}
+			if req.URL.Scheme != "http" && req.URL.Scheme != "https" {
+				return fmt.Errorf("%w: scheme %s", ErrUrlBlocked, req.URL.Scheme)
+			}
+			return p.checkHost(req.URL.Hostname())
+		},
+	}
+}
+
+// resolve returns the addresses of the host, failing if the host or one of its addresses is blocked.
+func (p URLPolicy) resolve(ctx context.Context, host string) ([]net.IP, error) {
+	if err := p.checkHost(host); err != nil {
+		return nil, err
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	ips := make([]net.IP, len(addrs))
+	for i, addr := range addrs {
+		if !p.allowed(host) && blockedIP(addr.IP) {
+			return nil, fmt.Errorf("%w: %s resolves to %s", ErrUrlBlocked, host, addr.IP)
+		}
+		ips[i] = addr.IP
+	}
+
+	return ips, nil
+}
+
+func (p URLPolicy) checkHost(host string) error {
+	if matchHost(p.DeniedHosts, host) || (len(p.AllowedHosts) > 0 && !p.allowed(host)) {
+		return fmt.Errorf("%w: %s", ErrUrlBlocked, host)
+	}
+	return nil
+}
+
+func (p URLPolicy) allowed(host string) bool {
+	return matchHost(p.AllowedHosts, host)
+}
+
+func (p URLPolicy) maxRedirects() int {
+	if p.MaxRedirects <= 0 {
+		return defaultMaxRedirects
+	}
+	return p.MaxRedirects
+}
+
+// matchHost reports whether the host is one of the patterns, "*.example.com" matches example.com and its
+// subdomains. Patterns with other wildcards, rejected by Validate, match no host.
+func matchHost(patterns []string, host string) bool {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if suffix := strings.TrimPrefix(pattern, "*."); suffix != pattern {
+			if suffix != "" && !strings.Contains(suffix, "*") && (host == suffix || strings.HasSuffix(host, "."+suffix)) {
+				return true
+			}
+		} else if host == pattern {
+			return true
+		}
+	}
+
+	return false
+}
+
+// blockedIP reports whether the address is not a public unicast address.
+func blockedIP(ip net.IP) bool {
+	if ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() || ip.IsMulticast() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast() {
+		return true
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func mustParseCIDR(s string) *net.IPNet {
+	_, network, err := net.ParseCIDR(s)
+	if err != nil {
+		panic(err)
+	}
+	return network
+}
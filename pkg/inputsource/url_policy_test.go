@@ -0,0 +1,196 @@
+package inputsource
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestURLPolicy_BlocksInternalTargets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "listen\nsilent\n")
+	}))
+	defer server.Close()
+
+	port := server.URL[strings.LastIndex(server.URL, ":")+1:]
+
+	tests := []struct {
+		name        string
+		url         string
+		policy      URLPolicy
+		expectedErr error
+	}{
+		{
+			name:        "loopback address",
+			url:         server.URL,
+			expectedErr: ErrUrlBlocked,
+		},
+		{
+			name:        "name resolving to loopback",
+			url:         "http://localhost:" + port,
+			expectedErr: ErrUrlBlocked,
+		},
+		{
+			name:        "metadata endpoint",
+			url:         "http://169.254.169.254/latest/meta-data/",
+			expectedErr: ErrUrlBlocked,
+		},
+		{
+			name:        "host not in allowlist",
+			url:         server.URL,
+			policy:      URLPolicy{AllowedHosts: []string{"words.example.com"}},
+			expectedErr: ErrUrlBlocked,
+		},
+		{
+			name:        "allowed host in denylist",
+			url:         server.URL,
+			policy:      URLPolicy{AllowedHosts: []string{"127.0.0.1"}, DeniedHosts: []string{"127.0.0.1"}},
+			expectedErr: ErrUrlBlocked,
+		},
+		{
+			name:   "allowed host",
+			url:    server.URL,
+			policy: URLPolicy{AllowedHosts: []string{"127.0.0.1"}},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewHttpUrlInputSource(tc.url, tc.policy, nil).GetWords()
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestURLPolicy_Redirects(t *testing.T) {
+	internal := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "listen\nsilent\n")
+	}))
+	defer internal.Close()
+
+	var redirector *httptest.Server
+	redirector = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/internal":
+			http.Redirect(w, r, strings.Replace(internal.URL, "127.0.0.1", "localhost", 1), http.StatusFound)
+		case "/loop":
+			http.Redirect(w, r, redirector.URL+"/loop", http.StatusFound)
+		default:
+			http.Redirect(w, r, internal.URL, http.StatusFound)
+		}
+	}))
+	defer redirector.Close()
+
+	tests := []struct {
+		name        string
+		path        string
+		expectedErr error
+	}{
+		{
+			name:        "redirect to a host that is not allowed",
+			path:        "/internal",
+			expectedErr: ErrUrlBlocked,
+		},
+		{
+			name:        "redirect loop",
+			path:        "/loop",
+			expectedErr: ErrUrlRedirects,
+		},
+		{
+			name: "redirect to an allowed host",
+			path: "/",
+		},
+	}
+
+	// every hop is checked again, localhost is not allowed although it is the same server
+	policy := URLPolicy{AllowedHosts: []string{"127.0.0.1"}, MaxRedirects: 3}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewHttpUrlInputSource(redirector.URL+tc.path, policy, nil).GetWords()
+
+			if !errors.Is(err, tc.expectedErr) {
+				t.Errorf("expected error %v, got %v", tc.expectedErr, err)
+			}
+		})
+	}
+}
+
+func TestBlockedIP(t *testing.T) {
+	tests := []struct {
+		ip       string
+		expected bool
+	}{
+		{"127.0.0.1", true},
+		{"10.1.2.3", true},
+		{"172.16.0.1", true},
+		{"192.168.1.1", true},
+		{"169.254.169.254", true},
+		{"100.64.0.1", true},
+		{"0.0.0.0", true},
+		{"::1", true},
+		{"fd00:ec2::254", true},
+		{"fe80::1", true},
+		{"::ffff:127.0.0.1", true},
+		{"93.184.216.34", false},
+		{"2606:2800:220:1:248:1893:25c8:1946", false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.ip, func(t *testing.T) {
+			if actual := blockedIP(net.ParseIP(tc.ip)); actual != tc.expected {
+				t.Errorf("expected %v, got %v", tc.expected, actual)
+			}
+		})
+	}
+}
+
+func TestMatchHost(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		host     string
+		expected bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "www.example.com", false},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.example.com", "example.com", true},
+		{"*.example.com", "EXAMPLE.com.", true},
+		{"*.example.com", "evilexample.com", false},
+		{"*example.com", "evilexample.com", false},
+		{"*example.com", "example.com", false},
+		{"*.", "example.com", false},
+	}
+
+	for _, tc := range tests {
+		if actual := matchHost([]string{tc.pattern}, tc.host); actual != tc.expected {
+			t.Errorf("matchHost(%q, %q): expected %v, got %v", tc.pattern, tc.host, tc.expected, actual)
+		}
+	}
+}
+
+func TestURLPolicy_Validate(t *testing.T) {
+	tests := []struct {
+		policy  URLPolicy
+		wantErr bool
+	}{
+		{policy: URLPolicy{AllowedHosts: []string{"example.com", "*.example.org"}}},
+		{policy: URLPolicy{AllowedHosts: []string{"*example.com"}}, wantErr: true},
+		{policy: URLPolicy{DeniedHosts: []string{"www.*.example.com"}}, wantErr: true},
+		{policy: URLPolicy{DeniedHosts: []string{"*."}}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		if err := tc.policy.Validate(); (err != nil) != tc.wantErr {
+			t.Errorf("%+v: expected error %v, got %v", tc.policy, tc.wantErr, err)
+		}
+	}
+}